@@ -2,10 +2,12 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
@@ -13,47 +15,132 @@ import (
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2/middleware/recover"
 
+	"appbox/appbox_server/internal/api/middleware"
 	"appbox/appbox_server/internal/api/router"
+	"appbox/appbox_server/internal/apitoken"
+	"appbox/appbox_server/internal/audit"
 	"appbox/appbox_server/internal/config"
+	"appbox/appbox_server/internal/metrics"
+	"appbox/appbox_server/internal/ratelimit"
+	"appbox/appbox_server/internal/rbac"
+	"appbox/appbox_server/internal/requestctx"
 	"appbox/appbox_server/internal/service"
+	"appbox/appbox_server/internal/tracing"
 	"appbox/appbox_server/pkg/logger"
 )
 
+type overrideFlag map[string]string
+
+func (f overrideFlag) String() string {
+	return fmt.Sprintf("%d overrides", len(f))
+}
+
+func (f overrideFlag) Set(value string) error {
+	key, val, ok := strings.Cut(value, "=")
+	if !ok || strings.TrimSpace(key) == "" {
+		return fmt.Errorf("expected key=value, got %q", value)
+	}
+	f[strings.TrimSpace(key)] = val
+	return nil
+}
+
 func main() {
-	cfg, err := config.Load()
+	overrides := overrideFlag{}
+	configPath := flag.String("config", "", "path to config file (default: $APPBOX_CONFIG, config/config.yaml, ./config.yaml)")
+	flag.Var(overrides, "set", "override a config field, e.g. --set provider.stellar.gateway_key=xxx (repeatable)")
+	checkConfig := flag.Bool("check-config", false, "validate config and exit")
+	flag.Parse()
+
+	cfg, err := config.Load(config.LoadOptions{Path: *configPath, Overrides: overrides})
+	if *checkConfig {
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Printf("config %q is valid: %d providers, default=%q\n", cfg.Path, len(cfg.Provider.Apps), cfg.Provider.Default)
+		return
+	}
 	if err != nil {
 		logger.Fatalf("load config failed: %v", err)
 	}
+	if err := logger.Setup(logger.Config{Level: cfg.Log.Level, Format: cfg.Log.Format}); err != nil {
+		logger.Fatalf("setup logger failed: %v", err)
+	}
+
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
+	if err != nil {
+		logger.Fatalf("setup tracing failed: %v", err)
+	}
+	if cfg.Tracing.Enabled {
+		logger.Infof("tracing enabled: exporter=%s sample_ratio=%v", cfg.Tracing.Exporter, cfg.Tracing.SampleRatio)
+	}
 
 	app := fiber.New(fiber.Config{
 		AppName:               "appbox_server",
 		DisableStartupMessage: true,
 		ReadTimeout:           cfg.Server.ReadTimeout,
 		WriteTimeout:          cfg.Server.WriteTimeout,
+		// 仅在配置了可信代理时采信代理头中的客户端 IP，否则使用连接来源地址
+		EnableTrustedProxyCheck: len(cfg.Server.TrustedProxies) > 0,
+		TrustedProxies:          cfg.Server.TrustedProxies,
+		ProxyHeader:             proxyHeader(cfg.Server),
+		EnableIPValidation:      true,
 	})
 
 	app.Use(recover.New())
+	app.Use(middleware.RequestID())
+	app.Use(middleware.AccessLog(cfg.AccessLog))
+	app.Use(middleware.Tracing())
+	app.Use(middleware.Metrics())
 	app.Use(cors.New(cors.Config{
 		AllowOrigins:     cfg.CORS.AllowOrigins,
-		AllowHeaders:     "Origin, Content-Type, Accept, X-App-Key",
+		AllowHeaders:     "Origin, Content-Type, Accept, X-App-Key, " + requestctx.HeaderRequestID + ", " + requestctx.HeaderTraceParent + ", " + requestctx.HeaderTraceState,
 		AllowMethods:     "GET,POST,PUT,DELETE,OPTIONS",
+		ExposeHeaders:    middleware.HeaderUpstreamAttempts + ", " + requestctx.HeaderRequestID,
 		AllowCredentials: true,
 	}))
 	registry := service.NewProviderRegistry(cfg.Provider.Default)
+	reloader := service.NewProviderReloader(registry)
+	if err := reloader.Apply(cfg); err != nil {
+		logger.Fatalf("register providers failed: %v", err)
+	}
 
-	if cfg.Provider.Stellar.Enabled {
-		stellarProvider := service.NewStellarProvider(cfg.Provider.Stellar)
-		registry.Register(stellarProvider.Name(), stellarProvider)
-		logger.Infof("provider registered: %s -> %s", stellarProvider.Name(), cfg.Provider.Stellar.BaseURL)
+	monitorCtx, stopMonitor := context.WithCancel(context.Background())
+	defer stopMonitor()
+	monitor := service.NewHealthMonitor(registry)
+	go monitor.Run(monitorCtx)
+	metrics.Registry.MustRegister(service.NewProviderCollector(registry, monitor))
+
+	var auditStore audit.Store
+	if cfg.Audit.Enabled {
+		auditStore, err = audit.OpenBoltStore(cfg.Audit.Path)
+		if err != nil {
+			logger.Fatalf("open audit store failed: %v", err)
+		}
+		defer auditStore.Close()
+		go audit.RunRetention(monitorCtx, auditStore, cfg.Audit.Retention, time.Hour)
+		logger.Infof("audit log enabled: path=%s retention=%s", cfg.Audit.Path, cfg.Audit.Retention)
 	}
 
-	if cfg.Provider.TinyText.Enabled {
-		tinytextProvider := service.NewTinyTextProvider(cfg.Provider.TinyText)
-		registry.Register(tinytextProvider.Name(), tinytextProvider)
-		logger.Infof("provider registered: %s -> %s", tinytextProvider.Name(), cfg.Provider.TinyText.BaseURL)
+	var tokenStore apitoken.Store
+	if cfg.APITokens.Enabled {
+		tokenStore, err = apitoken.OpenBoltStore(cfg.APITokens.Path)
+		if err != nil {
+			logger.Fatalf("open api token store failed: %v", err)
+		}
+		defer tokenStore.Close()
+		logger.Infof("api tokens enabled: path=%s", cfg.APITokens.Path)
 	}
 
-	router.SetupRoutes(app, registry)
+	authorizer := rbac.NewAuthorizer(cfg.RBAC)
+	limiter := ratelimit.NewLimiter(cfg.RateLimit)
+	metrics.Registry.MustRegister(limiter)
+	reloader.OnReload(func(next *config.Config) {
+		authorizer.Update(next.RBAC)
+		limiter.Update(next.RateLimit)
+	})
+
+	router.SetupRoutes(app, cfg, registry, monitor, auditStore, authorizer, tokenStore, limiter)
 
 	addr := fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.Port)
 	go func() {
@@ -63,6 +150,20 @@ func main() {
 		}
 	}()
 
+	if cfg.Reload.Watch {
+		go config.Watch(monitorCtx, cfg.Path, cfg.Reload.WatchInterval, func() {
+			_ = reloader.Reload("file changed")
+		})
+	}
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for range hup {
+			_ = reloader.Reload("SIGHUP")
+		}
+	}()
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
@@ -73,4 +174,14 @@ func main() {
 	if err := app.ShutdownWithContext(ctx); err != nil {
 		logger.Errorf("app shutdown failed: %v", err)
 	}
+	if err := shutdownTracing(ctx); err != nil {
+		logger.Errorf("tracing shutdown failed: %v", err)
+	}
+}
+
+func proxyHeader(server config.ServerConfig) string {
+	if len(server.TrustedProxies) == 0 {
+		return ""
+	}
+	return server.ProxyHeader
 }
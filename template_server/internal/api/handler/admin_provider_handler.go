@@ -1,12 +1,14 @@
 package handler
 
 import (
+	"errors"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/gofiber/fiber/v2"
 
+	"appbox/appbox_server/internal/api/middleware"
+	"appbox/appbox_server/internal/audit"
 	"appbox/appbox_server/internal/dto"
 	"appbox/appbox_server/internal/service"
 	"appbox/appbox_server/internal/util"
@@ -25,23 +27,26 @@ type AdminProviderHandler interface {
 
 type adminProviderHandler struct {
 	registry *service.ProviderRegistry
+	monitor  *service.HealthMonitor
+	audit    audit.Store
 }
 
-func NewAdminProviderHandler(registry *service.ProviderRegistry) AdminProviderHandler {
-	return &adminProviderHandler{registry: registry}
+func NewAdminProviderHandler(registry *service.ProviderRegistry, monitor *service.HealthMonitor, auditStore audit.Store) AdminProviderHandler {
+	return &adminProviderHandler{registry: registry, monitor: monitor, audit: auditStore}
 }
 
 func (h *adminProviderHandler) ListProviders(c *fiber.Ctx) error {
-	return c.JSON(dto.Response{
-		Code:      fiber.StatusOK,
-		Timestamp: time.Now().UnixMilli(),
-		Msg:       "success",
-		Data:      h.registry.List(),
-	})
+	items := h.registry.List()
+	for i := range items {
+		items[i].Health = h.monitor.Status(items[i].Key)
+		items[i].Version = items[i].Health.Version
+	}
+
+	return respond(c, fiber.StatusOK, "success", items)
 }
 
 func (h *adminProviderHandler) ListUsers(c *fiber.Ctx) error {
-	provider, err := h.resolveProvider(c)
+	provider, err := h.resolveProvider(c, service.CapabilityUsers)
 	if err != nil {
 		return h.fail(c, err)
 	}
@@ -51,141 +56,142 @@ func (h *adminProviderHandler) ListUsers(c *fiber.Ctx) error {
 	keyword := strings.TrimSpace(c.Query("keyword"))
 	page, pageSize = util.GetPaginationParams(page, pageSize)
 
-	result, err := provider.ListUsers(c.Context(), page, pageSize, keyword)
+	result, err := provider.ListUsers(c.UserContext(), page, pageSize, keyword)
 	if err != nil {
 		return h.fail(c, err)
 	}
 
-	return c.JSON(dto.Response{Code: fiber.StatusOK, Timestamp: time.Now().UnixMilli(), Msg: "success", Data: result})
+	return respond(c, fiber.StatusOK, "success", result)
 }
 
 func (h *adminProviderHandler) ListUserPlanets(c *fiber.Ctx) error {
-	provider, err := h.resolveProvider(c)
+	provider, err := h.resolveProvider(c, service.CapabilityPlanets)
 	if err != nil {
 		return h.fail(c, err)
 	}
 
 	userID, err := parseUintParam(c, "id")
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(dto.Response{Code: fiber.StatusBadRequest, Timestamp: time.Now().UnixMilli(), Msg: "Invalid user id"})
+		return respond(c, fiber.StatusBadRequest, "Invalid user id", nil)
 	}
 
 	page := c.QueryInt("page", 1)
 	pageSize := c.QueryInt("pageSize", c.QueryInt("page_size", 10))
 	page, pageSize = util.GetPaginationParams(page, pageSize)
 
-	result, err := provider.ListUserPlanets(c.Context(), userID, page, pageSize)
+	result, err := provider.ListUserPlanets(c.UserContext(), userID, page, pageSize)
 	if err != nil {
 		return h.fail(c, err)
 	}
 
-	return c.JSON(dto.Response{Code: fiber.StatusOK, Timestamp: time.Now().UnixMilli(), Msg: "success", Data: result})
+	return respond(c, fiber.StatusOK, "success", result)
 }
 
 func (h *adminProviderHandler) UpdateUser(c *fiber.Ctx) error {
-	provider, err := h.resolveProvider(c)
+	provider, err := h.resolveProvider(c, service.CapabilityUsers)
 	if err != nil {
 		return h.fail(c, err)
 	}
 
 	userID, err := parseUintParam(c, "id")
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(dto.Response{Code: fiber.StatusBadRequest, Timestamp: time.Now().UnixMilli(), Msg: "Invalid user id"})
+		return respond(c, fiber.StatusBadRequest, "Invalid user id", nil)
 	}
 
 	var req dto.AdminUserUpdateRequest
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(dto.Response{Code: fiber.StatusBadRequest, Timestamp: time.Now().UnixMilli(), Msg: "Invalid request body"})
+		return respond(c, fiber.StatusBadRequest, "Invalid request body", nil)
 	}
 
-	updated, err := provider.UpdateUser(c.Context(), userID, req)
+	updated, err := provider.UpdateUser(c.UserContext(), userID, req)
 	if err != nil {
 		return h.fail(c, err)
 	}
 
-	return c.JSON(dto.Response{Code: fiber.StatusOK, Timestamp: time.Now().UnixMilli(), Msg: "success", Data: updated})
+	return respond(c, fiber.StatusOK, "success", updated)
 }
 
 func (h *adminProviderHandler) DeleteUser(c *fiber.Ctx) error {
-	provider, err := h.resolveProvider(c)
+	provider, err := h.resolveProvider(c, service.CapabilityUsers)
 	if err != nil {
 		return h.fail(c, err)
 	}
 
 	userID, err := parseUintParam(c, "id")
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(dto.Response{Code: fiber.StatusBadRequest, Timestamp: time.Now().UnixMilli(), Msg: "Invalid user id"})
+		return respond(c, fiber.StatusBadRequest, "Invalid user id", nil)
 	}
 
-	if err := provider.DeleteUser(c.Context(), userID); err != nil {
+	if err := provider.DeleteUser(c.UserContext(), userID); err != nil {
 		return h.fail(c, err)
 	}
 
-	return c.JSON(dto.Response{Code: fiber.StatusOK, Timestamp: time.Now().UnixMilli(), Msg: "User deleted successfully"})
+	return respond(c, fiber.StatusOK, "User deleted successfully", nil)
 }
 
 func (h *adminProviderHandler) ListConfigs(c *fiber.Ctx) error {
-	provider, err := h.resolveProvider(c)
+	provider, err := h.resolveProvider(c, service.CapabilityConfigs)
 	if err != nil {
 		return h.fail(c, err)
 	}
 
-	result, err := provider.ListConfigs(c.Context())
+	result, err := provider.ListConfigs(c.UserContext())
 	if err != nil {
 		return h.fail(c, err)
 	}
 
-	return c.JSON(dto.Response{Code: fiber.StatusOK, Timestamp: time.Now().UnixMilli(), Msg: "success", Data: result})
+	return respond(c, fiber.StatusOK, "success", result)
 }
 
 func (h *adminProviderHandler) UpsertConfig(c *fiber.Ctx) error {
-	provider, err := h.resolveProvider(c)
+	provider, err := h.resolveProvider(c, service.CapabilityConfigs)
 	if err != nil {
 		return h.fail(c, err)
 	}
 
 	key := strings.TrimSpace(c.Params("key"))
 	if key == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(dto.Response{Code: fiber.StatusBadRequest, Timestamp: time.Now().UnixMilli(), Msg: "Config key is required"})
+		return respond(c, fiber.StatusBadRequest, "Config key is required", nil)
 	}
 
 	var req dto.AppConfigUpsertRequest
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(dto.Response{Code: fiber.StatusBadRequest, Timestamp: time.Now().UnixMilli(), Msg: "Invalid request body"})
+		return respond(c, fiber.StatusBadRequest, "Invalid request body", nil)
 	}
 
-	result, err := provider.UpsertConfig(c.Context(), key, req)
+	result, err := provider.UpsertConfig(c.UserContext(), key, req)
 	if err != nil {
 		return h.fail(c, err)
 	}
 
-	return c.JSON(dto.Response{Code: fiber.StatusOK, Timestamp: time.Now().UnixMilli(), Msg: "success", Data: result})
+	return respond(c, fiber.StatusOK, "success", result)
 }
 
 func (h *adminProviderHandler) DeleteConfig(c *fiber.Ctx) error {
-	provider, err := h.resolveProvider(c)
+	provider, err := h.resolveProvider(c, service.CapabilityConfigs)
 	if err != nil {
 		return h.fail(c, err)
 	}
 
 	key := strings.TrimSpace(c.Params("key"))
 	if key == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(dto.Response{Code: fiber.StatusBadRequest, Timestamp: time.Now().UnixMilli(), Msg: "Config key is required"})
+		return respond(c, fiber.StatusBadRequest, "Config key is required", nil)
 	}
 
-	if err := provider.DeleteConfig(c.Context(), key); err != nil {
+	if err := provider.DeleteConfig(c.UserContext(), key); err != nil {
 		return h.fail(c, err)
 	}
 
-	return c.JSON(dto.Response{Code: fiber.StatusOK, Timestamp: time.Now().UnixMilli(), Msg: "Config deleted successfully"})
+	return respond(c, fiber.StatusOK, "Config deleted successfully", nil)
 }
 
-func (h *adminProviderHandler) resolveProvider(c *fiber.Ctx) (service.AdminProvider, error) {
-	providerKey := strings.TrimSpace(c.Get("X-App-Key"))
-	if providerKey == "" {
-		providerKey = strings.TrimSpace(c.Query("app"))
+func (h *adminProviderHandler) resolveProvider(c *fiber.Ctx, capability service.Capability) (service.AdminProvider, error) {
+	provider, err := h.registry.ResolveFor(middleware.ProviderKey(c), capability)
+	if err != nil {
+		return nil, err
 	}
-	return h.registry.Resolve(providerKey)
+	c.Locals(middleware.LocalsProvider, provider.Name())
+	return service.Audit(service.Instrument(provider), h.audit), nil
 }
 
 func (h *adminProviderHandler) fail(c *fiber.Ctx, err error) error {
@@ -195,15 +201,18 @@ func (h *adminProviderHandler) fail(c *fiber.Ctx, err error) error {
 		if code < 400 || code > 599 {
 			code = fiber.StatusBadGateway
 		}
-		return c.Status(code).JSON(dto.Response{Code: code, Timestamp: time.Now().UnixMilli(), Msg: upErr.Message})
+		return respond(c, code, upErr.Message, nil)
 	}
 
 	msg := err.Error()
+	if errors.Is(err, service.ErrOperationNotSupported) {
+		return respond(c, fiber.StatusNotImplemented, msg, nil)
+	}
 	if strings.Contains(msg, "provider not found") {
-		return c.Status(fiber.StatusBadRequest).JSON(dto.Response{Code: fiber.StatusBadRequest, Timestamp: time.Now().UnixMilli(), Msg: msg})
+		return respond(c, fiber.StatusBadRequest, msg, nil)
 	}
 
-	return c.Status(fiber.StatusInternalServerError).JSON(dto.Response{Code: fiber.StatusInternalServerError, Timestamp: time.Now().UnixMilli(), Msg: "Internal server error"})
+	return respond(c, fiber.StatusInternalServerError, "Internal server error", nil)
 }
 
 func parseUintParam(c *fiber.Ctx, key string) (uint, error) {
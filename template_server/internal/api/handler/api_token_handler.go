@@ -0,0 +1,215 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"appbox/appbox_server/internal/apitoken"
+	"appbox/appbox_server/internal/audit"
+	"appbox/appbox_server/internal/config"
+	"appbox/appbox_server/internal/dto"
+	"appbox/appbox_server/internal/rbac"
+	"appbox/appbox_server/internal/requestctx"
+	"appbox/appbox_server/internal/service"
+	"appbox/appbox_server/pkg/logger"
+)
+
+// maxTokenNameLength 限制 token 名称长度，名称会出现在审计与日志的操作人字段中
+const maxTokenNameLength = 64
+
+type APITokenHandler interface {
+	ListTokens(c *fiber.Ctx) error
+	CreateToken(c *fiber.Ctx) error
+	RevokeToken(c *fiber.Ctx) error
+}
+
+type apiTokenHandler struct {
+	store      apitoken.Store
+	cfg        config.APITokenConfig
+	registry   *service.ProviderRegistry
+	authorizer *rbac.Authorizer
+	audit      audit.Store
+}
+
+// NewAPITokenHandler 创建 API token 管理 handler，store 为 nil 表示未启用 API token
+func NewAPITokenHandler(store apitoken.Store, cfg config.APITokenConfig, registry *service.ProviderRegistry, authorizer *rbac.Authorizer, auditStore audit.Store) APITokenHandler {
+	return &apiTokenHandler{store: store, cfg: cfg, registry: registry, authorizer: authorizer, audit: auditStore}
+}
+
+func (h *apiTokenHandler) ListTokens(c *fiber.Ctx) error {
+	if h.store == nil {
+		return respond(c, fiber.StatusServiceUnavailable, "API tokens are disabled", nil)
+	}
+
+	tokens, err := h.store.List(c.UserContext())
+	if err != nil {
+		logger.FromContext(c.UserContext()).Error("list api tokens failed", "error", err)
+		return respond(c, fiber.StatusInternalServerError, "Internal server error", nil)
+	}
+	now := time.Now()
+	items := make([]dto.APIToken, 0, len(tokens))
+	for _, token := range tokens {
+		items = append(items, token.DTO(now))
+	}
+	return respond(c, fiber.StatusOK, "success", items)
+}
+
+func (h *apiTokenHandler) CreateToken(c *fiber.Ctx) error {
+	if h.store == nil {
+		return respond(c, fiber.StatusServiceUnavailable, "API tokens are disabled", nil)
+	}
+
+	var req dto.APITokenCreateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return respond(c, fiber.StatusBadRequest, "Invalid request body", nil)
+	}
+	now := time.Now()
+	token, err := h.buildToken(req, now)
+	if err != nil {
+		return respond(c, fiber.StatusBadRequest, err.Error(), nil)
+	}
+	// 只能授予创建者自身拥有的权限
+	operator, identified := requestctx.OperatorFrom(c.UserContext())
+	scope := requestctx.Scope{Providers: token.Providers, Operations: token.Operations}
+	if provider, operation, missing := h.authorizer.Policy().MissingScope(operator, identified, scope); missing {
+		return respond(c, fiber.StatusForbidden, fmt.Sprintf("Permission denied: missing %s on %s", operation, provider),
+			dto.MissingPermission{Provider: provider, Operation: operation})
+	}
+	token.CreatedBy = requestctx.OperatorID(c.UserContext())
+
+	id, secret, hash, err := apitoken.NewSecret()
+	if err != nil {
+		logger.FromContext(c.UserContext()).Error("generate api token failed", "error", err)
+		return respond(c, fiber.StatusInternalServerError, "Internal server error", nil)
+	}
+	token.ID, token.Hash = id, hash
+	token.Prefix = secret[:len(apitoken.SecretPrefix)+6]
+	err = h.store.Create(c.UserContext(), token)
+	if errors.Is(err, apitoken.ErrNameUsed) {
+		return respond(c, fiber.StatusConflict, "API token name already in use", nil)
+	}
+	if err != nil {
+		logger.FromContext(c.UserContext()).Error("create api token failed", "error", err)
+		return respond(c, fiber.StatusInternalServerError, "Internal server error", nil)
+	}
+
+	h.record(c.UserContext(), "CreateToken", token.ID, req, now)
+	return respond(c, fiber.StatusOK, "success", dto.APITokenCreated{APIToken: token.DTO(now), Token: secret})
+}
+
+func (h *apiTokenHandler) RevokeToken(c *fiber.Ctx) error {
+	if h.store == nil {
+		return respond(c, fiber.StatusServiceUnavailable, "API tokens are disabled", nil)
+	}
+
+	now := time.Now()
+	id := strings.TrimSpace(c.Params("id"))
+	token, err := h.store.Revoke(c.UserContext(), id, now)
+	if errors.Is(err, apitoken.ErrNotFound) {
+		return respond(c, fiber.StatusNotFound, "API token not found", nil)
+	}
+	if err != nil {
+		logger.FromContext(c.UserContext()).Error("revoke api token failed", "error", err)
+		return respond(c, fiber.StatusInternalServerError, "Internal server error", nil)
+	}
+
+	h.record(c.UserContext(), "RevokeToken", id, nil, now)
+	return respond(c, fiber.StatusOK, "API token revoked successfully", token.DTO(now))
+}
+
+// buildToken 校验名称、授权范围与有效期，providers 须为当前已注册的 provider 或 *
+func (h *apiTokenHandler) buildToken(req dto.APITokenCreateRequest, now time.Time) (apitoken.Token, error) {
+	token := apitoken.Token{
+		Name:       strings.TrimSpace(req.Name),
+		Providers:  trimAll(req.Providers),
+		Operations: trimAll(req.Operations),
+		CreatedAt:  now,
+	}
+	if token.Name == "" || len(token.Name) > maxTokenNameLength {
+		return token, fmt.Errorf("name is required and must not exceed %d characters", maxTokenNameLength)
+	}
+
+	if len(token.Providers) == 0 {
+		return token, errors.New("providers is required")
+	}
+	registered := h.registry.Snapshot()
+	for _, provider := range token.Providers {
+		if _, ok := registered[provider]; !ok && provider != config.RBACWildcard {
+			return token, fmt.Errorf("unknown provider %q", provider)
+		}
+	}
+	if len(token.Operations) == 0 {
+		return token, errors.New("operations is required")
+	}
+	for _, operation := range token.Operations {
+		if !slices.Contains(rbac.TokenOperations, operation) && operation != config.RBACWildcard {
+			return token, fmt.Errorf("operation %q cannot be granted to api tokens", operation)
+		}
+	}
+
+	switch {
+	case req.ExpiresIn != "" && req.ExpiresAt != "":
+		return token, errors.New("expiresIn and expiresAt are mutually exclusive")
+	case req.ExpiresIn != "":
+		ttl, err := time.ParseDuration(req.ExpiresIn)
+		if err != nil || ttl <= 0 {
+			return token, fmt.Errorf("invalid expiresIn %q (expected e.g. 720h)", req.ExpiresIn)
+		}
+		token.ExpiresAt = now.Add(ttl)
+	case req.ExpiresAt != "":
+		expiresAt, err := time.Parse(time.RFC3339, req.ExpiresAt)
+		if err != nil || !expiresAt.After(now) {
+			return token, fmt.Errorf("invalid expiresAt %q (expected a future RFC3339 time)", req.ExpiresAt)
+		}
+		token.ExpiresAt = expiresAt
+	default:
+		token.ExpiresAt = now.Add(h.cfg.DefaultTTL)
+	}
+	if token.ExpiresAt.After(now.Add(h.cfg.MaxTTL)) {
+		return token, fmt.Errorf("expiry must not exceed %s", h.cfg.MaxTTL)
+	}
+	return token, nil
+}
+
+// record 将 token 的创建与撤销写入审计，provider 为空表示网关自身的操作
+func (h *apiTokenHandler) record(ctx context.Context, operation, targetID string, payload interface{}, start time.Time) {
+	if h.audit == nil {
+		return
+	}
+	entry := dto.AuditEntry{
+		Time:       start.UnixMilli(),
+		Operator:   requestctx.OperatorID(ctx),
+		ClientIP:   requestctx.ClientIP(ctx),
+		RequestID:  requestctx.RequestID(ctx),
+		Operation:  operation,
+		TargetID:   targetID,
+		Result:     audit.ResultSuccess,
+		StatusCode: fiber.StatusOK,
+		LatencyMs:  time.Since(start).Milliseconds(),
+	}
+	if payload != nil {
+		if encoded, err := json.Marshal(payload); err == nil {
+			entry.Payload = encoded
+		}
+	}
+	if err := h.audit.Append(context.WithoutCancel(ctx), entry); err != nil {
+		logger.FromContext(ctx).Error("write audit entry failed", "error", err)
+	}
+}
+
+func trimAll(values []string) []string {
+	items := make([]string, 0, len(values))
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			items = append(items, value)
+		}
+	}
+	return items
+}
@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"appbox/appbox_server/internal/audit"
+	"appbox/appbox_server/internal/dto"
+	"appbox/appbox_server/internal/util"
+	"appbox/appbox_server/pkg/logger"
+)
+
+type AuditHandler interface {
+	ListAudit(c *fiber.Ctx) error
+}
+
+type auditHandler struct {
+	store audit.Store
+}
+
+// NewAuditHandler 创建审计查询 handler，store 为 nil 表示审计已关闭
+func NewAuditHandler(store audit.Store) AuditHandler {
+	return &auditHandler{store: store}
+}
+
+func (h *auditHandler) ListAudit(c *fiber.Ctx) error {
+	if h.store == nil {
+		return respond(c, fiber.StatusServiceUnavailable, "Audit log is disabled", nil)
+	}
+
+	from, err := parseTimeQuery(c.Query("from"))
+	if err != nil {
+		return respond(c, fiber.StatusBadRequest, "Invalid from, expected RFC3339 or unix milliseconds", nil)
+	}
+	to, err := parseTimeQuery(c.Query("to"))
+	if err != nil {
+		return respond(c, fiber.StatusBadRequest, "Invalid to, expected RFC3339 or unix milliseconds", nil)
+	}
+
+	page := c.QueryInt("page", 1)
+	pageSize := c.QueryInt("pageSize", c.QueryInt("page_size", 10))
+	page, pageSize = util.GetPaginationParams(page, pageSize)
+
+	items, total, err := h.store.Query(c.UserContext(), audit.Filter{
+		Provider:  strings.TrimSpace(c.Query("provider")),
+		Operation: strings.TrimSpace(c.Query("operation")),
+		TargetID:  strings.TrimSpace(c.Query("targetId")),
+		Operator:  strings.TrimSpace(c.Query("operator")),
+		From:      from,
+		To:        to,
+		Page:      page,
+		PageSize:  pageSize,
+	})
+	if err != nil {
+		logger.FromContext(c.UserContext()).Error("query audit entries failed", "error", err)
+		return respond(c, fiber.StatusInternalServerError, "Internal server error", nil)
+	}
+
+	totalPages := int((total + int64(pageSize) - 1) / int64(pageSize))
+	return respond(c, fiber.StatusOK, "success", dto.PaginationResponse[dto.AuditEntry]{
+		Total:       total,
+		Page:        page,
+		PageSize:    pageSize,
+		TotalPages:  totalPages,
+		HasNext:     page < totalPages,
+		HasPrevious: page > 1,
+		Data:        items,
+	})
+}
+
+// parseTimeQuery 接受 RFC3339 或 unix 毫秒时间戳，空值返回零值
+func parseTimeQuery(raw string) (time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return time.Time{}, nil
+	}
+	if millis, err := strconv.ParseInt(raw, 10, 64); err == nil {
+		return time.UnixMilli(millis), nil
+	}
+	return time.Parse(time.RFC3339, raw)
+}
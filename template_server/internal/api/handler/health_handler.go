@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"appbox/appbox_server/internal/service"
+)
+
+type HealthHandler interface {
+	Health(c *fiber.Ctx) error
+	Livez(c *fiber.Ctx) error
+	Readyz(c *fiber.Ctx) error
+}
+
+type healthHandler struct {
+	monitor *service.HealthMonitor
+}
+
+func NewHealthHandler(monitor *service.HealthMonitor) HealthHandler {
+	return &healthHandler{monitor: monitor}
+}
+
+func (h *healthHandler) Health(c *fiber.Ctx) error {
+	status := "ok"
+	providers := h.monitor.Snapshot()
+	for _, health := range providers {
+		if health.Status != service.HealthStatusUp && health.Status != service.HealthStatusDisabled {
+			status = "degraded"
+			break
+		}
+	}
+
+	return respond(c, fiber.StatusOK, "ok", fiber.Map{
+		"service":   "appbox_server",
+		"status":    status,
+		"providers": providers,
+	})
+}
+
+func (h *healthHandler) Livez(c *fiber.Ctx) error {
+	return respond(c, fiber.StatusOK, "ok", nil)
+}
+
+func (h *healthHandler) Readyz(c *fiber.Ctx) error {
+	ready, reason := h.monitor.Ready()
+	if !ready {
+		return respond(c, fiber.StatusServiceUnavailable, reason, nil)
+	}
+	return respond(c, fiber.StatusOK, "ok", nil)
+}
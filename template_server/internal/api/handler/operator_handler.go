@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"appbox/appbox_server/internal/dto"
+	"appbox/appbox_server/internal/rbac"
+	"appbox/appbox_server/internal/requestctx"
+	"appbox/appbox_server/internal/service"
+)
+
+type OperatorHandler interface {
+	MyPermissions(c *fiber.Ctx) error
+}
+
+type operatorHandler struct {
+	authorizer *rbac.Authorizer
+	registry   *service.ProviderRegistry
+}
+
+func NewOperatorHandler(authorizer *rbac.Authorizer, registry *service.ProviderRegistry) OperatorHandler {
+	return &operatorHandler{authorizer: authorizer, registry: registry}
+}
+
+// MyPermissions 返回当前操作人在各已注册 provider 上允许的操作；RBAC 未启用时为全部操作，API token 为其授权范围
+func (h *operatorHandler) MyPermissions(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	operator, identified := requestctx.OperatorFrom(ctx)
+	policy := h.authorizer.Policy()
+	roles := policy.EffectiveRoles(operator.Roles, identified)
+
+	items := h.registry.List()
+	keys := make([]string, 0, len(items))
+	for _, item := range items {
+		keys = append(keys, item.Key)
+	}
+	providers, gateway := policy.Permissions(operator, identified, keys)
+	if roles == nil {
+		roles = []string{}
+	}
+
+	return respond(c, fiber.StatusOK, "success", dto.OperatorPermissions{
+		Operator:    requestctx.OperatorID(ctx),
+		Roles:       roles,
+		RBACEnabled: policy.Enabled(),
+		Providers:   providers,
+		Gateway:     gateway,
+	})
+}
@@ -0,0 +1,21 @@
+package handler
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"appbox/appbox_server/internal/dto"
+	"appbox/appbox_server/internal/requestctx"
+)
+
+// respond 按统一响应结构返回，并附带当前请求的 requestId 便于与网关及上游日志关联
+func respond(c *fiber.Ctx, status int, msg string, data interface{}) error {
+	return c.Status(status).JSON(dto.Response{
+		Code:      status,
+		Timestamp: time.Now().UnixMilli(),
+		Msg:       msg,
+		Data:      data,
+		RequestID: requestctx.RequestID(c.UserContext()),
+	})
+}
@@ -0,0 +1,225 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"appbox/appbox_server/internal/api/middleware"
+	"appbox/appbox_server/internal/config"
+	"appbox/appbox_server/internal/dto"
+	"appbox/appbox_server/internal/ratelimit"
+	"appbox/appbox_server/internal/rbac"
+	"appbox/appbox_server/internal/requestctx"
+	"appbox/appbox_server/internal/service"
+	"appbox/appbox_server/internal/upstream"
+	"appbox/appbox_server/internal/util"
+	"appbox/appbox_server/pkg/logger"
+)
+
+type SearchHandler interface {
+	SearchUsers(c *fiber.Ctx) error
+}
+
+type searchHandler struct {
+	registry   *service.ProviderRegistry
+	authorizer *rbac.Authorizer
+	limiter    *ratelimit.Limiter
+	timeout    time.Duration
+}
+
+func NewSearchHandler(registry *service.ProviderRegistry, authorizer *rbac.Authorizer, limiter *ratelimit.Limiter, cfg config.SearchConfig) SearchHandler {
+	return &searchHandler{registry: registry, authorizer: authorizer, limiter: limiter, timeout: cfg.Timeout}
+}
+
+// SearchUsers 并发调用各 provider 的 ListUsers 并合并结果；单个 provider 失败或超时不影响其他 provider。
+// 未指定 providers 时搜索全部支持用户管理且有权限的 provider，指定时不满足条件的 provider 记为 skipped
+func (h *searchHandler) SearchUsers(c *fiber.Ctx) error {
+	keyword := strings.TrimSpace(c.Query("keyword"))
+	if keyword == "" {
+		return respond(c, fiber.StatusBadRequest, "Keyword is required", nil)
+	}
+	_, pageSize := util.GetPaginationParams(1, c.QueryInt("pageSize", c.QueryInt("page_size", 10)))
+
+	snapshot := h.registry.Snapshot()
+	keys, explicit, err := searchTargets(c.Query("providers"), snapshot)
+	if err != nil {
+		return respond(c, fiber.StatusBadRequest, err.Error(), nil)
+	}
+
+	ctx := c.UserContext()
+	operator, identified := requestctx.OperatorFrom(ctx)
+	policy := h.authorizer.Policy()
+	if !explicit {
+		permitted := keys[:0]
+		for _, key := range keys {
+			if snapshot[key].Supports(service.CapabilityUsers) && policy.AllowedFor(operator, identified, key, rbac.OpListUsers) {
+				permitted = append(permitted, key)
+			}
+		}
+		keys = permitted
+		if len(keys) == 0 {
+			return respond(c, fiber.StatusForbidden, "Permission denied: missing "+rbac.OpListUsers+" on any provider",
+				dto.MissingPermission{Operation: rbac.OpListUsers})
+		}
+	}
+
+	c.Locals(middleware.LocalsProvider, strings.Join(keys, ","))
+
+	states := make([]dto.ProviderSearchState, len(keys))
+	results := make([][]dto.User, len(keys))
+	var wg sync.WaitGroup
+	for i, key := range keys {
+		// key 可能来自查询参数（引用请求缓冲区），限流与响应均使用注册的 provider 名称
+		provider := snapshot[key]
+		key = provider.Name()
+		state := &states[i]
+		state.Provider = key
+		switch {
+		case !provider.Supports(service.CapabilityUsers):
+			skipSearch(state, fiber.StatusNotImplemented, fmt.Sprintf("%s does not support %s", key, service.CapabilityUsers))
+		case !policy.AllowedFor(operator, identified, key, rbac.OpListUsers):
+			skipSearch(state, fiber.StatusForbidden, "Permission denied: missing "+rbac.OpListUsers+" on "+key)
+		default:
+			if decision := h.limiter.Allow(requestctx.OperatorID(ctx), key, rbac.OpListUsers); !decision.Allowed {
+				skipSearch(state, fiber.StatusTooManyRequests, "Rate limit exceeded: "+decision.Rule)
+			}
+		}
+		if state.Status != "" {
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = h.searchProvider(ctx, provider, keyword, pageSize, state)
+		}()
+	}
+	wg.Wait()
+
+	response := dto.UserSearchResponse{Keyword: keyword, Items: make([]dto.UserSearchItem, 0), Providers: states}
+	succeeded, failed := 0, 0
+	for i, state := range states {
+		switch state.Status {
+		case dto.SearchStatusOK:
+			succeeded++
+		case dto.SearchStatusFailed:
+			failed++
+		}
+		for _, user := range results[i] {
+			response.Items = append(response.Items, dto.UserSearchItem{Provider: state.Provider, User: user})
+		}
+	}
+	response.Partial = succeeded < len(states)
+
+	// 指定的 provider 全部被跳过时与未指定时一致返回错误，data 中保留各 provider 的跳过原因
+	if code, msg, ok := allSkipped(states); ok {
+		return respond(c, code, msg, response)
+	}
+	if succeeded == 0 && failed > 0 {
+		return respond(c, fiber.StatusBadGateway, "All providers failed", response)
+	}
+	return respond(c, fiber.StatusOK, "success", response)
+}
+
+// searchProvider 在独立的超时内查询单个 provider，并把结果状态写入 state
+func (h *searchHandler) searchProvider(ctx context.Context, provider service.AdminProvider, keyword string, pageSize int, state *dto.ProviderSearchState) []dto.User {
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	start := time.Now()
+	result, err := service.Instrument(provider).ListUsers(ctx, 1, pageSize, keyword)
+	state.LatencyMs = time.Since(start).Milliseconds()
+	if err != nil {
+		state.Status = dto.SearchStatusFailed
+		state.Result = upstream.Classify(err)
+		state.StatusCode, state.Error = searchError(err, state.Result)
+		logger.FromContext(ctx).Warn("user search failed", "provider", state.Provider, "result", state.Result, "error", err)
+		return nil
+	}
+
+	state.Status = dto.SearchStatusOK
+	state.Result = upstream.ResultOK
+	state.Total = result.Total
+	state.Count = len(result.Data)
+	return result.Data
+}
+
+// searchTargets 解析逗号分隔的 providers 参数，未指定时返回全部已注册的 provider（按 key 排序）
+func searchTargets(raw string, snapshot map[string]service.AdminProvider) ([]string, bool, error) {
+	keys := make([]string, 0, len(snapshot))
+	seen := make(map[string]bool)
+	for _, item := range strings.Split(raw, ",") {
+		key := strings.TrimSpace(item)
+		if key == "" || seen[key] {
+			continue
+		}
+		if _, ok := snapshot[key]; !ok {
+			return nil, true, fmt.Errorf("provider not found: %s", key)
+		}
+		seen[key] = true
+		keys = append(keys, key)
+	}
+	if len(keys) > 0 {
+		return keys, true, nil
+	}
+
+	for key := range snapshot {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys, false, nil
+}
+
+// allSkipped 在所有 provider 都被跳过时返回错误状态码：原因一致时沿用该原因（403、501、429），混合时返回 400
+func allSkipped(states []dto.ProviderSearchState) (int, string, bool) {
+	if len(states) == 0 {
+		return 0, "", false
+	}
+	code := states[0].StatusCode
+	for _, state := range states {
+		if state.Status != dto.SearchStatusSkipped {
+			return 0, "", false
+		}
+		if state.StatusCode != code {
+			code = 0
+		}
+	}
+	switch code {
+	case fiber.StatusForbidden:
+		return code, "Permission denied: missing " + rbac.OpListUsers + " on all requested providers", true
+	case fiber.StatusNotImplemented:
+		return code, fmt.Sprintf("No requested provider supports %s", service.CapabilityUsers), true
+	case fiber.StatusTooManyRequests:
+		return code, "Rate limit exceeded on all requested providers", true
+	default:
+		return fiber.StatusBadRequest, "No requested provider can be searched", true
+	}
+}
+
+func skipSearch(state *dto.ProviderSearchState, statusCode int, msg string) {
+	state.Status = dto.SearchStatusSkipped
+	state.StatusCode = statusCode
+	state.Error = msg
+}
+
+// searchError 与单 provider 接口保持一致：上游错误沿用其状态码与信息，其余错误不透出细节
+func searchError(err error, result string) (int, string) {
+	upErr := &service.UpstreamError{}
+	if asUpstreamError(err, upErr) {
+		code := upErr.StatusCode
+		if code < 400 || code > 599 {
+			code = fiber.StatusBadGateway
+		}
+		return code, upErr.Message
+	}
+	if result == upstream.ResultTimeout {
+		return fiber.StatusGatewayTimeout, "Upstream timeout"
+	}
+	return fiber.StatusBadGateway, "Upstream request failed"
+}
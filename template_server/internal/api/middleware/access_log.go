@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"log/slog"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"appbox/appbox_server/internal/config"
+	"appbox/appbox_server/internal/upstream"
+	"appbox/appbox_server/pkg/logger"
+)
+
+// LocalsProvider 为 handler 解析出的 provider key，供访问日志记录
+const LocalsProvider = "provider"
+
+// AccessLog 记录每个请求的方法、路由模板、状态码、耗时、provider、客户端 IP 与上游状态；
+// 4xx/5xx 始终记录，成功请求按 SampleRatio 采样，ExcludePaths 中的路径不记录
+func AccessLog(cfg config.AccessLogConfig) fiber.Handler {
+	exact := make(map[string]bool, len(cfg.ExcludePaths))
+	prefixes := make([]string, 0)
+	for _, path := range cfg.ExcludePaths {
+		if prefix, ok := strings.CutSuffix(path, "/*"); ok {
+			prefixes = append(prefixes, prefix+"/")
+		} else {
+			exact[path] = true
+		}
+	}
+	excluded := func(path string) bool {
+		if exact[path] {
+			return true
+		}
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(path, prefix) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return func(c *fiber.Ctx) error {
+		if !cfg.Enabled || excluded(c.Path()) {
+			return c.Next()
+		}
+		start := time.Now()
+
+		err := c.Next()
+
+		status := responseStatus(c, err)
+		if status < fiber.StatusBadRequest && cfg.SampleRatio < 1 && rand.Float64() >= cfg.SampleRatio {
+			return err
+		}
+
+		attrs := []slog.Attr{
+			slog.String("method", c.Method()),
+			slog.String("route", routePattern(c, err)),
+			slog.String("path", c.Path()),
+			slog.Int("status", status),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.String("client_ip", c.IP()),
+		}
+		if provider, ok := c.Locals(LocalsProvider).(string); ok && provider != "" {
+			attrs = append(attrs, slog.String("provider", provider))
+		}
+		if stats := upstream.CallStatsFromContext(c.UserContext()); stats.Attempts() > 0 {
+			attrs = append(attrs, slog.Int("upstream_status", stats.StatusCode()), slog.Int("upstream_attempts", stats.Attempts()))
+		}
+		if err != nil {
+			attrs = append(attrs, slog.String("error", err.Error()))
+		}
+
+		level := slog.LevelInfo
+		switch {
+		case status >= fiber.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= fiber.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+		logger.FromContext(c.UserContext()).LogAttrs(c.UserContext(), level, "access", attrs...)
+		return err
+	}
+}
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"go.opentelemetry.io/otel/trace"
+
+	"appbox/appbox_server/internal/identity"
+	"appbox/appbox_server/internal/requestctx"
+	"appbox/appbox_server/internal/tracing"
+	"appbox/appbox_server/pkg/logger"
+)
+
+// LocalsOperator 为 Fiber Locals 中保存操作人标识的键
+const LocalsOperator = "operator"
+
+// Identity 识别操作人并连同客户端 IP 写入用户 context 与 logger 字段；
+// 来自不可信地址的身份请求头返回 403，无效、撤销或过期的 gate token / API token 返回 401，
+// required 为 true 时未携带身份信息同样返回 401
+func Identity(extractor *identity.Extractor, required bool) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		// Fiber 返回的字符串引用 fasthttp 复用的请求缓冲区，而操作人会被 span（异步导出）等在请求结束后继续持有，需要复制
+		ctx := requestctx.WithClientIP(c.UserContext(), strings.Clone(c.IP()))
+		c.SetUserContext(ctx)
+
+		operator, ok, err := extractor.Extract(ctx, identity.Request{
+			RemoteIP: c.Context().RemoteIP(),
+			Header:   func(name string) string { return strings.Clone(c.Get(name)) },
+			Cookie:   func(name string) string { return strings.Clone(c.Cookies(name)) },
+		})
+		if err != nil {
+			log := logger.FromContext(ctx)
+			switch {
+			case errors.Is(err, identity.ErrUntrustedSource):
+				log.Warn("rejected identity headers from untrusted source", "remote_ip", c.Context().RemoteIP().String())
+				return reject(c, fiber.StatusForbidden, "Identity headers are not accepted from this source", nil)
+			case errors.Is(err, identity.ErrTokenExpired):
+				return reject(c, fiber.StatusUnauthorized, "Token expired", nil)
+			case errors.Is(err, identity.ErrInvalidToken):
+				log.Warn("rejected invalid token", "error", err)
+				return reject(c, fiber.StatusUnauthorized, "Invalid token", nil)
+			default:
+				log.Error("resolve operator identity failed", "error", err)
+				return reject(c, fiber.StatusInternalServerError, "Internal server error", nil)
+			}
+		}
+		if !ok {
+			if required {
+				return reject(c, fiber.StatusUnauthorized, "Operator identity is required", nil)
+			}
+			return c.Next()
+		}
+
+		c.Locals(LocalsOperator, operator.ID)
+		ctx = requestctx.WithOperator(ctx, operator)
+		ctx = logger.With(ctx, logger.KeyOperator, operator.ID)
+		trace.SpanFromContext(ctx).SetAttributes(tracing.AttrOperator.String(operator.ID))
+		c.SetUserContext(ctx)
+		return c.Next()
+	}
+}
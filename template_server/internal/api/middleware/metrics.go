@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"appbox/appbox_server/internal/metrics"
+)
+
+// Metrics 按方法、路由模板与状态码记录入站请求数与耗时；未匹配路由的请求归入 route="unmatched"，避免原始路径进入标签
+func Metrics() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		metrics.HTTPInFlight.Inc()
+		defer metrics.HTTPInFlight.Dec()
+		start := time.Now()
+
+		err := c.Next()
+
+		status := responseStatus(c, err)
+		route := routePattern(c, err)
+		// c.Method() 引用 fasthttp 复用的请求缓冲区，而标签值会被 Prometheus 长期保存，需要复制
+		method := strings.Clone(c.Method())
+		metrics.HTTPRequests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+		metrics.HTTPDuration.WithLabelValues(method, route).Observe(time.Since(start).Seconds())
+		return err
+	}
+}
+
+// routePattern 返回匹配到的路由模板，未匹配任何路由（fiber 返回 404 错误）时为 unmatched
+func routePattern(c *fiber.Ctx, err error) string {
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) && fiberErr.Code == fiber.StatusNotFound {
+		return "unmatched"
+	}
+	return c.Route().Path
+}
+
+// responseStatus 返回最终响应状态码；handler 返回的 fiber.Error 要到 ErrorHandler 才写入响应，需要提前取出
+func responseStatus(c *fiber.Ctx, err error) int {
+	if err == nil {
+		return c.Response().StatusCode()
+	}
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return fiberErr.Code
+	}
+	return fiber.StatusInternalServerError
+}
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+
+	"appbox/appbox_server/internal/ratelimit"
+	"appbox/appbox_server/internal/requestctx"
+	"appbox/appbox_server/internal/service"
+	"appbox/appbox_server/pkg/logger"
+)
+
+// RateLimit 按操作人、目标 provider 与 operation 的读写类别限流，超限返回 429 并附带 Retry-After（秒）
+func RateLimit(limiter *ratelimit.Limiter, registry *service.ProviderRegistry, operation string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		// 先按注册中心解析，使用注册的 provider 名称限流；未知 key 直接拒绝，避免任意请求头产生新的令牌桶与指标序列
+		resolved, err := registry.Resolve(ProviderKey(c))
+		if err != nil {
+			return reject(c, fiber.StatusBadRequest, err.Error(), nil)
+		}
+		provider := resolved.Name()
+		ctx := c.UserContext()
+		decision := limiter.Allow(requestctx.OperatorID(ctx), provider, operation)
+		if decision.Allowed {
+			return c.Next()
+		}
+
+		retryAfter := int(math.Max(1, math.Ceil(decision.RetryAfter.Seconds())))
+		logger.FromContext(ctx).Warn("rate limited", "rule", decision.Rule, "provider", provider,
+			"operation", operation, "retry_after", retryAfter)
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(retryAfter))
+		return reject(c, fiber.StatusTooManyRequests, "Rate limit exceeded: "+decision.Rule, nil)
+	}
+}
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"appbox/appbox_server/internal/requestctx"
+	"appbox/appbox_server/pkg/logger"
+)
+
+// LocalsRequestID 为 Fiber Locals 中保存请求 ID 的键
+const LocalsRequestID = "requestid"
+
+// RequestID 接受或生成 X-Request-ID，并延续入站 traceparent，写入 Fiber Locals、用户 context（含携带 request_id 的 logger）与响应头
+func RequestID() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		// 请求 ID 会写入异步导出的 span，需要复制出 fasthttp 复用的请求头缓冲区
+		id := strings.Clone(requestctx.NormalizeRequestID(c.Get(requestctx.HeaderRequestID)))
+		trace := requestctx.ContinueTrace(c.Get(requestctx.HeaderTraceParent), c.Get(requestctx.HeaderTraceState))
+
+		c.Locals(LocalsRequestID, id)
+		ctx := requestctx.WithRequestID(c.UserContext(), id)
+		ctx = logger.With(ctx, logger.KeyRequestID, id)
+		c.SetUserContext(requestctx.WithTrace(ctx, trace))
+		c.Set(requestctx.HeaderRequestID, id)
+		return c.Next()
+	}
+}
@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"appbox/appbox_server/internal/dto"
+	"appbox/appbox_server/internal/requestctx"
+)
+
+// reject 以统一响应结构中止请求，与 handler 的响应格式保持一致
+func reject(c *fiber.Ctx, status int, msg string, data interface{}) error {
+	return c.Status(status).JSON(dto.Response{
+		Code:      status,
+		Timestamp: time.Now().UnixMilli(),
+		Msg:       msg,
+		Data:      data,
+		RequestID: requestctx.RequestID(c.UserContext()),
+	})
+}
@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.43.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"appbox/appbox_server/internal/requestctx"
+	"appbox/appbox_server/internal/tracing"
+)
+
+// Tracing 为每个请求创建 server span，延续入站 traceparent；span 名称在路由匹配后取路由模板（如 GET /api/v1/admin/users/:id）
+func Tracing() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !tracing.Enabled() {
+			return c.Next()
+		}
+
+		header := make(http.Header)
+		c.Request().Header.VisitAll(func(key, value []byte) {
+			header.Add(string(key), string(value))
+		})
+		ctx := otel.GetTextMapPropagator().Extract(c.UserContext(), propagation.HeaderCarrier(header))
+		// span 由 batch exporter 在请求结束后读取，方法与路径需要复制出 fasthttp 复用的缓冲区
+		method, path := strings.Clone(c.Method()), strings.Clone(c.Path())
+		ctx, span := tracing.Tracer().Start(ctx, method+" "+path,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(method),
+				semconv.URLPath(path),
+				tracing.AttrRequestID.String(requestctx.RequestID(ctx)),
+			),
+		)
+		defer span.End()
+		c.SetUserContext(ctx)
+
+		err := c.Next()
+
+		route := c.Route().Path
+		span.SetName(method + " " + route)
+		status := responseStatus(c, err)
+		span.SetAttributes(semconv.HTTPRoute(route), semconv.HTTPResponseStatusCode(status))
+		if err != nil {
+			span.RecordError(err)
+		}
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+		return err
+	}
+}
@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+
+	"appbox/appbox_server/internal/upstream"
+)
+
+const HeaderUpstreamAttempts = "X-Upstream-Attempts"
+
+func UpstreamStats() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx, stats := upstream.WithCallStats(c.UserContext())
+		c.SetUserContext(ctx)
+
+		err := c.Next()
+		if attempts := stats.Attempts(); attempts > 0 {
+			c.Set(HeaderUpstreamAttempts, strconv.Itoa(attempts))
+		}
+		return err
+	}
+}
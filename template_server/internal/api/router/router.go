@@ -1,39 +1,62 @@
 package router
 
 import (
-	"time"
-
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
 
 	"appbox/appbox_server/internal/api/handler"
-	"appbox/appbox_server/internal/dto"
+	"appbox/appbox_server/internal/api/middleware"
+	"appbox/appbox_server/internal/apitoken"
+	"appbox/appbox_server/internal/audit"
+	"appbox/appbox_server/internal/config"
+	"appbox/appbox_server/internal/identity"
+	"appbox/appbox_server/internal/metrics"
+	"appbox/appbox_server/internal/ratelimit"
+	"appbox/appbox_server/internal/rbac"
 	"appbox/appbox_server/internal/service"
 )
 
-func SetupRoutes(app *fiber.App, registry *service.ProviderRegistry) {
-	adminProviderHandler := handler.NewAdminProviderHandler(registry)
+func SetupRoutes(app *fiber.App, cfg *config.Config, registry *service.ProviderRegistry, monitor *service.HealthMonitor, auditStore audit.Store, authorizer *rbac.Authorizer, tokenStore apitoken.Store, limiter *ratelimit.Limiter) {
+	adminProviderHandler := handler.NewAdminProviderHandler(registry, monitor, auditStore)
+	auditHandler := handler.NewAuditHandler(auditStore)
+	operatorHandler := handler.NewOperatorHandler(authorizer, registry)
+	apiTokenHandler := handler.NewAPITokenHandler(tokenStore, cfg.APITokens, registry, authorizer, auditStore)
+	searchHandler := handler.NewSearchHandler(registry, authorizer, limiter, cfg.Search)
+	healthHandler := handler.NewHealthHandler(monitor)
+
+	app.Get("/livez", healthHandler.Livez)
+	app.Get("/readyz", healthHandler.Readyz)
+	app.Get("/metrics", adaptor.HTTPHandler(metrics.Handler()))
 
 	api := app.Group("/api")
 	v1 := api.Group("/v1")
 
-	v1.Get("/health", func(c *fiber.Ctx) error {
-		return c.JSON(dto.Response{
-			Code:      fiber.StatusOK,
-			Timestamp: time.Now().UnixMilli(),
-			Msg:       "ok",
-			Data: fiber.Map{
-				"service": "appbox_server",
-			},
-		})
-	})
+	v1.Get("/health", healthHandler.Health)
 
-	admin := v1.Group("/admin")
+	admin := v1.Group("/admin", middleware.Identity(identity.NewExtractor(cfg.Identity, cfg.Server.TrustedProxies, tokenStore), cfg.Identity.Required), middleware.UpstreamStats())
+	// guard 依次做权限校验与限流，两者都先按注册中心解析 provider，未知 provider 返回 400
+	guard := func(operation string, h fiber.Handler) []fiber.Handler {
+		return []fiber.Handler{
+			middleware.Authorize(authorizer, registry, operation),
+			middleware.RateLimit(limiter, registry, operation),
+			h,
+		}
+	}
+	admin.Get("/me/permissions", operatorHandler.MyPermissions)
+	admin.Get("/audit", middleware.AuthorizeGateway(authorizer, rbac.OpListAudit), auditHandler.ListAudit)
+	// token 管理始终要求已识别的非 token 操作人，未开启 RBAC 时匿名请求也不能签发 token
+	tokens := admin.Group("/tokens", middleware.RequireOperator(), middleware.AuthorizeGateway(authorizer, rbac.OpManageTokens))
+	tokens.Get("", apiTokenHandler.ListTokens)
+	tokens.Post("", apiTokenHandler.CreateToken)
+	tokens.Delete("/:id", apiTokenHandler.RevokeToken)
 	admin.Get("/providers", adminProviderHandler.ListProviders)
-	admin.Get("/users", adminProviderHandler.ListUsers)
-	admin.Get("/users/:id/planets", adminProviderHandler.ListUserPlanets)
-	admin.Put("/users/:id", adminProviderHandler.UpdateUser)
-	admin.Delete("/users/:id", adminProviderHandler.DeleteUser)
-	admin.Get("/configs", adminProviderHandler.ListConfigs)
-	admin.Put("/configs/:key", adminProviderHandler.UpsertConfig)
-	admin.Delete("/configs/:key", adminProviderHandler.DeleteConfig)
+	// 跨 provider 搜索在 handler 内逐个 provider 做权限校验与限流
+	admin.Get("/search/users", searchHandler.SearchUsers)
+	admin.Get("/users", guard(rbac.OpListUsers, adminProviderHandler.ListUsers)...)
+	admin.Get("/users/:id/planets", guard(rbac.OpListUserPlanets, adminProviderHandler.ListUserPlanets)...)
+	admin.Put("/users/:id", guard(rbac.OpUpdateUser, adminProviderHandler.UpdateUser)...)
+	admin.Delete("/users/:id", guard(rbac.OpDeleteUser, adminProviderHandler.DeleteUser)...)
+	admin.Get("/configs", guard(rbac.OpListConfigs, adminProviderHandler.ListConfigs)...)
+	admin.Put("/configs/:key", guard(rbac.OpUpsertConfig, adminProviderHandler.UpsertConfig)...)
+	admin.Delete("/configs/:key", guard(rbac.OpDeleteConfig, adminProviderHandler.DeleteConfig)...)
 }
@@ -0,0 +1,170 @@
+package apitoken
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	tokensBucket = []byte("api_tokens")
+	hashesBucket = []byte("api_token_hashes")
+)
+
+// lastUsedInterval 限制 LastUsedAt 的写入频率，避免每个请求都产生写事务
+const lastUsedInterval = time.Minute
+
+// boltStore 以 bbolt 保存 token：tokensBucket 按 ID 存记录，hashesBucket 为哈希到 ID 的索引
+type boltStore struct {
+	db *bolt.DB
+}
+
+func OpenBoltStore(path string) (Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("create api token dir failed: %w", err)
+	}
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("open api token store %s failed: %w", path, err)
+	}
+	if err := db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{tokensBucket, hashesBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("init api token store failed: %w", err)
+	}
+	return &boltStore{db: db}, nil
+}
+
+func (s *boltStore) Create(_ context.Context, token Token) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		tokens := tx.Bucket(tokensBucket)
+		if tokens.Get([]byte(token.ID)) != nil {
+			return fmt.Errorf("api token id %s already exists", token.ID)
+		}
+		if err := tokens.ForEach(func(_, value []byte) error {
+			var existing Token
+			if err := json.Unmarshal(value, &existing); err != nil {
+				return err
+			}
+			if existing.Name == token.Name && existing.Status(token.CreatedAt) == StatusActive {
+				return ErrNameUsed
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+		if err := putToken(tokens, token); err != nil {
+			return err
+		}
+		return tx.Bucket(hashesBucket).Put([]byte(token.Hash), []byte(token.ID))
+	})
+}
+
+func (s *boltStore) List(_ context.Context) ([]Token, error) {
+	items := make([]Token, 0)
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(tokensBucket).ForEach(func(_, value []byte) error {
+			var token Token
+			if err := json.Unmarshal(value, &token); err != nil {
+				return err
+			}
+			items = append(items, token)
+			return nil
+		})
+	})
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].CreatedAt.After(items[j].CreatedAt)
+	})
+	return items, err
+}
+
+func (s *boltStore) Revoke(_ context.Context, id string, at time.Time) (Token, error) {
+	var token Token
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		tokens := tx.Bucket(tokensBucket)
+		found, err := getToken(tokens, id)
+		if err != nil {
+			return err
+		}
+		token = found
+		if !token.RevokedAt.IsZero() {
+			return nil
+		}
+		token.RevokedAt = at
+		return putToken(tokens, token)
+	})
+	return token, err
+}
+
+func (s *boltStore) Authenticate(_ context.Context, secret string, now time.Time) (Token, error) {
+	var token Token
+	err := s.db.View(func(tx *bolt.Tx) error {
+		id := tx.Bucket(hashesBucket).Get([]byte(Hash(secret)))
+		if id == nil {
+			return ErrInvalid
+		}
+		found, err := getToken(tx.Bucket(tokensBucket), string(id))
+		token = found
+		return err
+	})
+	if err != nil {
+		return Token{}, err
+	}
+	switch token.Status(now) {
+	case StatusRevoked:
+		return Token{}, ErrRevoked
+	case StatusExpired:
+		return Token{}, ErrExpired
+	}
+
+	if now.Sub(token.LastUsedAt) >= lastUsedInterval {
+		token.LastUsedAt = now
+		// 在写事务内重新读取，避免覆盖并发的撤销
+		_ = s.db.Update(func(tx *bolt.Tx) error {
+			tokens := tx.Bucket(tokensBucket)
+			current, err := getToken(tokens, token.ID)
+			if err != nil {
+				return err
+			}
+			current.LastUsedAt = now
+			return putToken(tokens, current)
+		})
+	}
+	return token, nil
+}
+
+func (s *boltStore) Close() error {
+	return s.db.Close()
+}
+
+func getToken(bucket *bolt.Bucket, id string) (Token, error) {
+	value := bucket.Get([]byte(id))
+	if value == nil {
+		return Token{}, ErrNotFound
+	}
+	var token Token
+	if err := json.Unmarshal(value, &token); err != nil {
+		return Token{}, fmt.Errorf("decode api token %s failed: %w", id, err)
+	}
+	return token, nil
+}
+
+func putToken(bucket *bolt.Bucket, token Token) error {
+	value, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+	return bucket.Put([]byte(token.ID), value)
+}
@@ -0,0 +1,114 @@
+// Package apitoken 管理网关签发的 API token：本地只保存 token 的 SHA-256 哈希与授权范围，
+// 自动化脚本通过 Authorization: Bearer 使用 token 访问管理接口。
+package apitoken
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"appbox/appbox_server/internal/dto"
+)
+
+const (
+	// SecretPrefix 便于在日志与代码扫描中识别 token 明文
+	SecretPrefix = "abx_"
+
+	StatusActive  = "active"
+	StatusExpired = "expired"
+	StatusRevoked = "revoked"
+)
+
+var (
+	ErrNotFound = errors.New("api token not found")
+	ErrInvalid  = errors.New("invalid api token")
+	ErrExpired  = errors.New("api token expired")
+	ErrRevoked  = errors.New("api token revoked")
+	ErrNameUsed = errors.New("api token name already in use")
+)
+
+// Token 为持久化的 token 记录，Hash 为明文的 SHA-256
+type Token struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	Prefix     string    `json:"prefix"`
+	Hash       string    `json:"hash"`
+	Providers  []string  `json:"providers"`
+	Operations []string  `json:"operations"`
+	CreatedBy  string    `json:"createdBy"`
+	CreatedAt  time.Time `json:"createdAt"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+	RevokedAt  time.Time `json:"revokedAt"`
+	LastUsedAt time.Time `json:"lastUsedAt"`
+}
+
+type Store interface {
+	// Create 保存新 token；名称与未撤销、未过期的 token 重复时返回 ErrNameUsed，
+	// 因为操作人 ID 为 token:<name>，同名会在审计与限流中无法区分
+	Create(ctx context.Context, token Token) error
+	List(ctx context.Context) ([]Token, error)
+	// Revoke 标记 token 已撤销并返回更新后的记录，已撤销的 token 保持原撤销时间
+	Revoke(ctx context.Context, id string, at time.Time) (Token, error)
+	// Authenticate 按明文查找 token，并校验未撤销、未过期
+	Authenticate(ctx context.Context, secret string, now time.Time) (Token, error)
+	Close() error
+}
+
+// NewSecret 生成 token 明文及其哈希，ID 与明文独立生成
+func NewSecret() (id, secret, hash string, err error) {
+	idBytes := make([]byte, 8)
+	secretBytes := make([]byte, 32)
+	if _, err = rand.Read(idBytes); err != nil {
+		return "", "", "", err
+	}
+	if _, err = rand.Read(secretBytes); err != nil {
+		return "", "", "", err
+	}
+	secret = SecretPrefix + base64.RawURLEncoding.EncodeToString(secretBytes)
+	return hex.EncodeToString(idBytes), secret, Hash(secret), nil
+}
+
+func Hash(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// Status 返回 token 在 now 时刻的状态
+func (t Token) Status(now time.Time) string {
+	switch {
+	case !t.RevokedAt.IsZero():
+		return StatusRevoked
+	case !t.ExpiresAt.IsZero() && !now.Before(t.ExpiresAt):
+		return StatusExpired
+	default:
+		return StatusActive
+	}
+}
+
+// DTO 转换为对外返回的元数据
+func (t Token) DTO(now time.Time) dto.APIToken {
+	return dto.APIToken{
+		ID:         t.ID,
+		Name:       t.Name,
+		Prefix:     t.Prefix,
+		Providers:  t.Providers,
+		Operations: t.Operations,
+		CreatedBy:  t.CreatedBy,
+		CreatedAt:  unixMilli(t.CreatedAt),
+		ExpiresAt:  unixMilli(t.ExpiresAt),
+		RevokedAt:  unixMilli(t.RevokedAt),
+		LastUsedAt: unixMilli(t.LastUsedAt),
+		Status:     t.Status(now),
+	}
+}
+
+func unixMilli(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixMilli()
+}
@@ -0,0 +1,130 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"appbox/appbox_server/internal/dto"
+)
+
+var entriesBucket = []byte("audit_entries")
+
+// boltStore 以 bbolt 单文件存储审计记录，key 为 8 字节纳秒时间戳 + 8 字节自增序号（大端），天然按时间排序
+type boltStore struct {
+	db *bolt.DB
+}
+
+func OpenBoltStore(path string) (Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("create audit dir failed: %w", err)
+	}
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("open audit store %s failed: %w", path, err)
+	}
+	if err := db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(entriesBucket)
+		return err
+	}); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("init audit store failed: %w", err)
+	}
+	return &boltStore{db: db}, nil
+}
+
+func timeKey(t time.Time, seq uint64) []byte {
+	key := make([]byte, 16)
+	binary.BigEndian.PutUint64(key[:8], uint64(t.UnixNano()))
+	binary.BigEndian.PutUint64(key[8:], seq)
+	return key
+}
+
+func (s *boltStore) Append(_ context.Context, entry dto.AuditEntry) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(entriesBucket)
+		seq, err := bucket.NextSequence()
+		if err != nil {
+			return err
+		}
+		key := timeKey(time.UnixMilli(entry.Time), seq)
+		entry.ID = hex.EncodeToString(key)
+		value, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		return bucket.Put(key, value)
+	})
+}
+
+func (s *boltStore) Query(_ context.Context, filter Filter) ([]dto.AuditEntry, int64, error) {
+	offset := (filter.Page - 1) * filter.PageSize
+	items := make([]dto.AuditEntry, 0, filter.PageSize)
+	var total int64
+
+	var lower []byte
+	if !filter.From.IsZero() {
+		lower = timeKey(filter.From, 0)
+	}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket(entriesBucket).Cursor()
+		var key, value []byte
+		if filter.To.IsZero() {
+			key, value = cursor.Last()
+		} else {
+			// 定位到 To 之后的第一条，再回退到不晚于 To 的最后一条
+			key, value = cursor.Seek(timeKey(filter.To.Add(time.Nanosecond), 0))
+			if key == nil {
+				key, value = cursor.Last()
+			} else {
+				key, value = cursor.Prev()
+			}
+		}
+		for ; key != nil; key, value = cursor.Prev() {
+			if lower != nil && bytes.Compare(key, lower) < 0 {
+				break
+			}
+			var entry dto.AuditEntry
+			if err := json.Unmarshal(value, &entry); err != nil {
+				return fmt.Errorf("decode audit entry %x failed: %w", key, err)
+			}
+			if !filter.matches(entry) {
+				continue
+			}
+			if total >= int64(offset) && len(items) < filter.PageSize {
+				items = append(items, entry)
+			}
+			total++
+		}
+		return nil
+	})
+	return items, total, err
+}
+
+func (s *boltStore) Prune(_ context.Context, before time.Time) (int, error) {
+	upper := timeKey(before, 0)
+	removed := 0
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket(entriesBucket).Cursor()
+		for key, _ := cursor.First(); key != nil && bytes.Compare(key, upper) < 0; key, _ = cursor.Next() {
+			if err := cursor.Delete(); err != nil {
+				return err
+			}
+			removed++
+		}
+		return nil
+	})
+	return removed, err
+}
+
+func (s *boltStore) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,28 @@
+package audit
+
+import (
+	"context"
+	"time"
+
+	"appbox/appbox_server/pkg/logger"
+)
+
+// RunRetention 定期删除超过 retention 的审计记录，直到 ctx 取消
+func RunRetention(ctx context.Context, store Store, retention, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		removed, err := store.Prune(ctx, time.Now().Add(-retention))
+		if err != nil {
+			logger.Errorf("audit prune failed: %v", err)
+		} else if removed > 0 {
+			logger.Infof("audit prune removed %d entries older than %s", removed, retention)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,42 @@
+// Package audit 持久化变更类管理操作的审计记录，并支持按条件分页查询。
+package audit
+
+import (
+	"context"
+	"time"
+
+	"appbox/appbox_server/internal/dto"
+)
+
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+)
+
+// Filter 为查询条件，零值字段不参与过滤；时间范围为闭区间
+type Filter struct {
+	Provider  string
+	Operation string
+	TargetID  string
+	Operator  string
+	From      time.Time
+	To        time.Time
+	Page      int
+	PageSize  int
+}
+
+type Store interface {
+	Append(ctx context.Context, entry dto.AuditEntry) error
+	// Query 按时间倒序返回匹配的记录与总数
+	Query(ctx context.Context, filter Filter) ([]dto.AuditEntry, int64, error)
+	// Prune 删除早于 before 的记录，返回删除条数
+	Prune(ctx context.Context, before time.Time) (int, error)
+	Close() error
+}
+
+func (f Filter) matches(entry dto.AuditEntry) bool {
+	return (f.Provider == "" || entry.Provider == f.Provider) &&
+		(f.Operation == "" || entry.Operation == f.Operation) &&
+		(f.TargetID == "" || entry.TargetID == f.TargetID) &&
+		(f.Operator == "" || entry.Operator == f.Operator)
+}
@@ -4,25 +4,119 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"slices"
 	"strings"
 	"time"
 
 	"gopkg.in/yaml.v3"
+
+	"appbox/appbox_server/pkg/logger"
 )
 
 const defaultAllowOrigins = "https://appbox.xdarren.com,http://localhost:5173,http://127.0.0.1:5173,http://localhost:4173,http://127.0.0.1:4173"
 
 type Config struct {
-	Server   ServerConfig
-	CORS     CORSConfig
-	Provider ProviderConfig
+	Path      string
+	Overrides map[string]string
+	Server    ServerConfig
+	CORS      CORSConfig
+	Provider  ProviderConfig
+	Reload    ReloadConfig
+	Tracing   TracingConfig
+	Log       LogConfig
+	AccessLog AccessLogConfig
+	Audit     AuditConfig
+	Identity  IdentityConfig
+	RBAC      RBACConfig
+	APITokens APITokenConfig
+	RateLimit RateLimitConfig
+	Search    SearchConfig
+}
+
+// SearchConfig 控制跨 provider 用户搜索：每个 provider 的调用在 Timeout 内未完成即记为超时，不影响其他 provider 的结果
+type SearchConfig struct {
+	Timeout time.Duration
+}
+
+// APITokenConfig 控制网关签发的 API token：未指定有效期的 token 使用 DefaultTTL，任何 token 的有效期不超过 MaxTTL
+type APITokenConfig struct {
+	Enabled    bool
+	Path       string
+	DefaultTTL time.Duration
+	MaxTTL     time.Duration
+}
+
+const (
+	IdentityModeHeader = "header"
+	IdentityModeJWT    = "jwt"
+)
+
+// IdentityConfig 控制操作人身份识别：header 模式读取 nginx auth_request 写入的请求头，
+// 仅采信来自 server.trusted_proxies（未配置时为本机回环地址）的连接；jwt 模式校验 gate 签发的 cookie
+type IdentityConfig struct {
+	Mode        string
+	Required    bool
+	UserHeader  string
+	RolesHeader string
+	JWT         GateJWTConfig
+}
+
+// GateJWTConfig 为 gate cookie 中 HS256 JWT 的校验参数，Secret 支持 env:/file: 引用
+type GateJWTConfig struct {
+	Cookie     string
+	Secret     string
+	Issuer     string
+	Audience   string
+	UserClaim  string
+	RolesClaim string
+	Leeway     time.Duration
+}
+
+// LogConfig 控制日志输出：Level 支持热加载，Format 变更需重启
+type LogConfig struct {
+	Level  string
+	Format string
+}
+
+// TracingConfig 控制 OpenTelemetry 链路追踪；Exporter 为 otlp 时 Endpoint 为空则沿用 OTEL_EXPORTER_OTLP_* 环境变量
+type TracingConfig struct {
+	Enabled     bool
+	Exporter    string
+	Endpoint    string
+	Insecure    bool
+	ServiceName string
+	SampleRatio float64
+}
+
+type ReloadConfig struct {
+	Watch         bool
+	WatchInterval time.Duration
 }
 
+// ServerConfig 中 TrustedProxies 为可信反向代理（IP 或 CIDR），仅来自这些地址的请求才采信 ProxyHeader 中的客户端 IP
 type ServerConfig struct {
-	Host         string
-	Port         int
-	ReadTimeout  time.Duration
-	WriteTimeout time.Duration
+	Host           string
+	Port           int
+	ReadTimeout    time.Duration
+	WriteTimeout   time.Duration
+	TrustedProxies []string
+	ProxyHeader    string
+}
+
+// AccessLogConfig 控制访问日志：SampleRatio 只作用于成功请求，4xx/5xx 始终记录；
+// ExcludePaths 支持精确路径与以 /* 结尾的前缀
+type AccessLogConfig struct {
+	Enabled      bool
+	SampleRatio  float64
+	ExcludePaths []string
+}
+
+// AuditConfig 控制变更类管理操作的审计记录，超过 Retention 的记录由后台定期清理
+type AuditConfig struct {
+	Enabled   bool
+	Path      string
+	Retention time.Duration
 }
 
 type CORSConfig struct {
@@ -30,119 +124,349 @@ type CORSConfig struct {
 }
 
 type ProviderConfig struct {
-	Default  string
-	Stellar  StellarProviderConfig
-	TinyText TinyTextProviderConfig
+	Default string
+	Apps    []AppProviderConfig
 }
 
-type StellarProviderConfig struct {
-	Enabled     bool
-	Name        string
-	BaseURL     string
-	GatewayKey  string
-	GatewayHead string
-	Timeout     time.Duration
+type AppProviderConfig struct {
+	Enabled             bool
+	Name                string
+	DisplayName         string
+	Description         string
+	Capabilities        []string
+	BaseURL             string
+	PathPrefix          string
+	GatewayKey          string
+	GatewayKeySecondary string
+	GatewayHead         string
+	AuthMode            string
+	Timeout             time.Duration
+	TLS                 TLSConfig
+	Transport           TransportConfig
+	Bulkhead            BulkheadConfig
+	Retry               RetryConfig
+	Breaker             CircuitBreakerConfig
+	Health              HealthCheckConfig
 }
 
-type TinyTextProviderConfig struct {
-	Enabled     bool
-	Name        string
-	BaseURL     string
-	GatewayKey  string
-	GatewayHead string
-	Timeout     time.Duration
+type TransportConfig struct {
+	MaxIdleConns          int
+	MaxIdleConnsPerHost   int
+	MaxConnsPerHost       int
+	DialTimeout           time.Duration
+	KeepAlive             time.Duration
+	TLSHandshakeTimeout   time.Duration
+	ResponseHeaderTimeout time.Duration
+	IdleConnTimeout       time.Duration
+	DisableKeepAlives     bool
+	HTTP2                 bool
+}
+
+type BulkheadConfig struct {
+	MaxInFlight int
+	MaxWait     time.Duration
+}
+
+type HealthCheckConfig struct {
+	Enabled  bool
+	Path     string
+	Interval time.Duration
+	Timeout  time.Duration
+}
+
+type CircuitBreakerConfig struct {
+	Enabled          bool
+	FailureRatio     float64
+	MinRequests      int
+	Window           time.Duration
+	CoolDown         time.Duration
+	HalfOpenRequests int
 }
 
-func Load() (*Config, error) {
-	cfgPath, err := resolveConfigPath()
+type RetryConfig struct {
+	MaxAttempts       int
+	InitialBackoff    time.Duration
+	MaxBackoff        time.Duration
+	Jitter            float64
+	RetryOnStatus     []int
+	IdempotentMethods []string
+}
+
+// LoadOptions 描述配置来源：Path 为空时依次尝试 APPBOX_CONFIG 与默认路径，
+// Overrides 为 --set 传入的 `provider.stellar.gateway_key=xxx` 形式覆盖项
+type LoadOptions struct {
+	Path      string
+	Overrides map[string]string
+}
+
+func Load(opts LoadOptions) (*Config, error) {
+	cfgPath, err := resolveConfigPath(opts.Path)
 	if err != nil {
 		return nil, err
 	}
+	return LoadFile(cfgPath, opts.Overrides)
+}
 
+// LoadFile 按 默认值 < YAML < 环境变量 < 命令行覆盖 的顺序合并配置，
+// 并一次性校验全部字段，存在问题时返回汇总了所有问题的 *ValidationError
+func LoadFile(cfgPath string, overrides map[string]string) (*Config, error) {
 	raw := defaultRawConfig()
 	data, err := os.ReadFile(cfgPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read config file %q: %w", cfgPath, err)
 	}
-	if err := yaml.Unmarshal(data, &raw); err != nil {
+
+	var problems problemList
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
 		return nil, fmt.Errorf("failed to parse config file %q: %w", cfgPath, err)
 	}
+	problems.checkUnknownFields(&root, reflect.TypeOf(raw), "")
+	if err := root.Decode(&raw); err != nil {
+		typeErr, ok := err.(*yaml.TypeError)
+		if !ok {
+			return nil, fmt.Errorf("failed to parse config file %q: %w", cfgPath, err)
+		}
+		for _, msg := range typeErr.Errors {
+			problems.add("(yaml)", "%s", msg)
+		}
+	}
+	applyOverrides(&raw, os.LookupEnv, overrides, &problems)
 
 	cfg := &Config{
+		Path:      cfgPath,
+		Overrides: overrides,
 		Server: ServerConfig{
-			Host:         normalizeString(raw.Server.Host, "0.0.0.0"),
-			Port:         normalizeInt(raw.Server.Port, 8090),
-			ReadTimeout:  parseDuration(raw.Server.ReadTimeout, 10*time.Second),
-			WriteTimeout: parseDuration(raw.Server.WriteTimeout, 10*time.Second),
+			Host:           normalizeString(raw.Server.Host, "0.0.0.0"),
+			Port:           problems.nonNegative("server.port", raw.Server.Port, 8090),
+			ReadTimeout:    problems.duration("server.read_timeout", raw.Server.ReadTimeout, 10*time.Second),
+			WriteTimeout:   problems.duration("server.write_timeout", raw.Server.WriteTimeout, 10*time.Second),
+			TrustedProxies: normalizeList(raw.Server.TrustedProxies),
+			ProxyHeader:    normalizeString(raw.Server.ProxyHeader, "X-Forwarded-For"),
 		},
 		CORS: CORSConfig{
 			AllowOrigins: normalizeString(raw.CORS.AllowOrigins, defaultAllowOrigins),
 		},
 		Provider: ProviderConfig{
 			Default: strings.TrimSpace(raw.Provider.Default),
-			Stellar: StellarProviderConfig{
-				Enabled:     raw.Provider.Stellar.Enabled,
-				Name:        normalizeString(raw.Provider.Stellar.Name, "stellar"),
-				BaseURL:     normalizeBaseURL(raw.Provider.Stellar.BaseURL),
-				GatewayKey:  strings.TrimSpace(raw.Provider.Stellar.GatewayKey),
-				GatewayHead: normalizeString(raw.Provider.Stellar.GatewayHead, "X-Gateway-Key"),
-				Timeout:     parseDuration(raw.Provider.Stellar.Timeout, 10*time.Second),
-			},
-			TinyText: TinyTextProviderConfig{
-				Enabled:     raw.Provider.TinyText.Enabled,
-				Name:        normalizeString(raw.Provider.TinyText.Name, "tinytext"),
-				BaseURL:     normalizeBaseURL(raw.Provider.TinyText.BaseURL),
-				GatewayKey:  strings.TrimSpace(raw.Provider.TinyText.GatewayKey),
-				GatewayHead: normalizeString(raw.Provider.TinyText.GatewayHead, "X-Gateway-Key"),
-				Timeout:     parseDuration(raw.Provider.TinyText.Timeout, 10*time.Second),
-			},
+			Apps:    make([]AppProviderConfig, 0, len(raw.Provider.Apps)),
+		},
+		Reload: ReloadConfig{
+			Watch:         raw.Reload.Watch == nil || *raw.Reload.Watch,
+			WatchInterval: problems.duration("reload.watch_interval", raw.Reload.WatchInterval, 2*time.Second),
 		},
+		Tracing: buildTracingConfig(raw.Tracing, &problems),
+		Log: LogConfig{
+			Level:  strings.ToLower(normalizeString(raw.Log.Level, "info")),
+			Format: strings.ToLower(normalizeString(raw.Log.Format, "text")),
+		},
+	}
+	for i, proxy := range cfg.Server.TrustedProxies {
+		problems.ipOrCIDR(fmt.Sprintf("server.trusted_proxies[%d]", i), proxy)
+	}
+	cfg.AccessLog = buildAccessLogConfig(raw.AccessLog, &problems)
+	cfg.Audit = AuditConfig{
+		Enabled:   raw.Audit.Enabled == nil || *raw.Audit.Enabled,
+		Path:      normalizeString(raw.Audit.Path, "data/audit.db"),
+		Retention: problems.duration("audit.retention", raw.Audit.Retention, 90*24*time.Hour),
+	}
+	cfg.Identity = buildIdentityConfig(raw.Identity, &problems)
+	cfg.APITokens = APITokenConfig{
+		Enabled:    raw.APITokens.Enabled,
+		Path:       normalizeString(raw.APITokens.Path, "data/tokens.db"),
+		DefaultTTL: problems.duration("api_tokens.default_ttl", raw.APITokens.DefaultTTL, 90*24*time.Hour),
+		MaxTTL:     problems.duration("api_tokens.max_ttl", raw.APITokens.MaxTTL, 365*24*time.Hour),
+	}
+	cfg.Search = SearchConfig{
+		Timeout: problems.duration("search.timeout", raw.Search.Timeout, 3*time.Second),
+	}
+	if cfg.APITokens.DefaultTTL > cfg.APITokens.MaxTTL {
+		problems.add("api_tokens.default_ttl", "must not exceed api_tokens.max_ttl (%s), got %s", cfg.APITokens.MaxTTL, cfg.APITokens.DefaultTTL)
+	}
+	if _, err := logger.ParseLevel(cfg.Log.Level); err != nil {
+		problems.add("log.level", "must be one of debug, info, warn, error, got %q", raw.Log.Level)
+	}
+	if cfg.Log.Format != "text" && cfg.Log.Format != "json" {
+		problems.add("log.format", "must be one of text, json, got %q", raw.Log.Format)
+	}
+	if cfg.Server.Port > 65535 {
+		problems.add("server.port", "must be between 1 and 65535, got %d", cfg.Server.Port)
 	}
 
-	if cfg.Provider.Stellar.Enabled {
-		if cfg.Provider.Stellar.Name == "" {
-			return nil, fmt.Errorf("provider.stellar.name is required when stellar provider is enabled")
+	seen := make(map[string]string, len(raw.Provider.Apps))
+	enabled := make(map[string]bool, len(raw.Provider.Apps))
+	for i, item := range raw.Provider.Apps {
+		path := fmt.Sprintf("provider.apps[%d]", i)
+		app := AppProviderConfig{
+			Enabled:             item.Enabled,
+			Name:                strings.TrimSpace(item.Name),
+			DisplayName:         strings.TrimSpace(item.DisplayName),
+			Description:         strings.TrimSpace(item.Description),
+			Capabilities:        normalizeCapabilities(item.Capabilities),
+			BaseURL:             normalizeBaseURL(item.BaseURL),
+			PathPrefix:          normalizePathPrefix(item.PathPrefix, "/admin"),
+			GatewayKey:          strings.TrimSpace(item.GatewayKey),
+			GatewayKeySecondary: strings.TrimSpace(item.GatewayKeySecondary),
+			GatewayHead:         normalizeString(item.GatewayHead, "X-Gateway-Key"),
+			AuthMode:            strings.ToLower(normalizeString(item.AuthMode, "header")),
+			Timeout:             problems.duration(path+".timeout", item.Timeout, 10*time.Second),
+			TLS:                 buildTLSConfig(item.TLS),
+			Transport:           buildTransportConfig(item.Transport, path+".transport", &problems),
+			Bulkhead: BulkheadConfig{
+				MaxInFlight: problems.limit(path+".bulkhead.max_in_flight", item.Bulkhead.MaxInFlight, 100),
+				MaxWait:     problems.optionalDuration(path+".bulkhead.max_wait", item.Bulkhead.MaxWait, 0),
+			},
+			Retry:   buildRetryConfig(item.Retry, path+".retry", &problems),
+			Breaker: buildCircuitBreakerConfig(item.CircuitBreaker, path+".circuit_breaker", &problems),
+			Health: HealthCheckConfig{
+				Enabled:  item.Health.Enabled == nil || *item.Health.Enabled,
+				Path:     normalizePathPrefix(item.Health.Path, "/health"),
+				Interval: problems.duration(path+".health.interval", item.Health.Interval, 30*time.Second),
+				Timeout:  problems.duration(path+".health.timeout", item.Health.Timeout, 3*time.Second),
+			},
 		}
-		if cfg.Provider.Stellar.BaseURL == "" {
-			return nil, fmt.Errorf("provider.stellar.base_url is required when stellar provider is enabled")
+
+		if app.Name == "" {
+			problems.add(path+".name", "is required")
+		} else if previous, ok := seen[app.Name]; ok {
+			problems.add(path+".name", "duplicate provider name %q (already declared at %s)", app.Name, previous)
+		} else {
+			seen[app.Name] = path
 		}
-		if cfg.Provider.Stellar.GatewayKey == "" {
-			return nil, fmt.Errorf("provider.stellar.gateway_key is required when stellar provider is enabled")
+		if app.BaseURL != "" {
+			problems.baseURL(path+".base_url", app.BaseURL)
 		}
-	}
-
-	if cfg.Provider.TinyText.Enabled {
-		if cfg.Provider.TinyText.Name == "" {
-			return nil, fmt.Errorf("provider.tinytext.name is required when tinytext provider is enabled")
+		for j, capability := range item.Capabilities {
+			if name := strings.ToLower(strings.TrimSpace(capability)); !slices.Contains(knownCapabilities, name) {
+				problems.add(fmt.Sprintf("%s.capabilities[%d]", path, j), "must be one of %s, got %q", strings.Join(knownCapabilities, ", "), capability)
+			}
 		}
-		if cfg.Provider.TinyText.BaseURL == "" {
-			return nil, fmt.Errorf("provider.tinytext.base_url is required when tinytext provider is enabled")
+		if app.AuthMode != "header" && app.AuthMode != "hmac" {
+			problems.add(path+".auth_mode", "must be one of header, hmac, got %q", item.AuthMode)
 		}
-		if cfg.Provider.TinyText.GatewayKey == "" {
-			return nil, fmt.Errorf("provider.tinytext.gateway_key is required when tinytext provider is enabled")
+		if app.Enabled {
+			if app.BaseURL == "" {
+				problems.add(path+".base_url", "is required when provider is enabled")
+			}
+			if app.GatewayKey == "" {
+				problems.add(path+".gateway_key", "is required when provider is enabled")
+			} else {
+				problems.secretRef(path+".gateway_key", app.GatewayKey)
+			}
+			if app.GatewayKeySecondary != "" {
+				problems.secretRef(path+".gateway_key_secondary", app.GatewayKeySecondary)
+			}
+			problems.tls(path+".tls", app.BaseURL, app.TLS)
+			if app.Name != "" {
+				enabled[app.Name] = true
+			}
+			if cfg.Provider.Default == "" {
+				cfg.Provider.Default = app.Name
+			}
 		}
+		cfg.Provider.Apps = append(cfg.Provider.Apps, app)
 	}
 
-	if cfg.Provider.Default == "" && cfg.Provider.Stellar.Enabled {
-		cfg.Provider.Default = cfg.Provider.Stellar.Name
+	cfg.RBAC = buildRBACConfig(raw.RBAC, seen, &problems)
+	cfg.RateLimit = buildRateLimitConfig(raw.RateLimit, seen, &problems)
+
+	if def := strings.TrimSpace(raw.Provider.Default); def != "" && !enabled[def] {
+		if _, declared := seen[def]; declared {
+			problems.add("provider.default", "provider %q is disabled", def)
+		} else {
+			problems.add("provider.default", "unknown provider %q", def)
+		}
 	}
-	if cfg.Provider.Default == "" && cfg.Provider.TinyText.Enabled {
-		cfg.Provider.Default = cfg.Provider.TinyText.Name
+
+	if len(problems) > 0 {
+		return nil, &ValidationError{File: cfgPath, Problems: problems}
 	}
 	return cfg, nil
 }
 
 type rawConfig struct {
-	Server   rawServerConfig   `yaml:"server"`
-	CORS     rawCORSConfig     `yaml:"cors"`
-	Provider rawProviderConfig `yaml:"provider"`
+	Server    rawServerConfig    `yaml:"server"`
+	CORS      rawCORSConfig      `yaml:"cors"`
+	Provider  rawProviderConfig  `yaml:"provider"`
+	Reload    rawReloadConfig    `yaml:"reload"`
+	Tracing   rawTracingConfig   `yaml:"tracing"`
+	Log       rawLogConfig       `yaml:"log"`
+	AccessLog rawAccessLogConfig `yaml:"access_log"`
+	Audit     rawAuditConfig     `yaml:"audit"`
+	Identity  rawIdentityConfig  `yaml:"identity"`
+	RBAC      rawRBACConfig      `yaml:"rbac"`
+	APITokens rawAPITokenConfig  `yaml:"api_tokens"`
+	RateLimit rawRateLimitConfig `yaml:"rate_limit"`
+	Search    rawSearchConfig    `yaml:"search"`
+}
+
+type rawSearchConfig struct {
+	Timeout string `yaml:"timeout"`
+}
+
+type rawAPITokenConfig struct {
+	Enabled    bool   `yaml:"enabled"`
+	Path       string `yaml:"path"`
+	DefaultTTL string `yaml:"default_ttl"`
+	MaxTTL     string `yaml:"max_ttl"`
+}
+
+type rawAuditConfig struct {
+	Enabled   *bool  `yaml:"enabled"`
+	Path      string `yaml:"path"`
+	Retention string `yaml:"retention"`
+}
+
+type rawIdentityConfig struct {
+	Mode        string           `yaml:"mode"`
+	Required    bool             `yaml:"required"`
+	UserHeader  string           `yaml:"user_header"`
+	RolesHeader string           `yaml:"roles_header"`
+	JWT         rawGateJWTConfig `yaml:"jwt"`
+}
+
+type rawGateJWTConfig struct {
+	Cookie     string `yaml:"cookie"`
+	Secret     string `yaml:"secret"`
+	Issuer     string `yaml:"issuer"`
+	Audience   string `yaml:"audience"`
+	UserClaim  string `yaml:"user_claim"`
+	RolesClaim string `yaml:"roles_claim"`
+	Leeway     string `yaml:"leeway"`
+}
+
+type rawLogConfig struct {
+	Level  string `yaml:"level"`
+	Format string `yaml:"format"`
+}
+
+type rawTracingConfig struct {
+	Enabled     bool     `yaml:"enabled"`
+	Exporter    string   `yaml:"exporter"`
+	Endpoint    string   `yaml:"endpoint"`
+	Insecure    bool     `yaml:"insecure"`
+	ServiceName string   `yaml:"service_name"`
+	SampleRatio *float64 `yaml:"sample_ratio"`
+}
+
+type rawReloadConfig struct {
+	Watch         *bool  `yaml:"watch"`
+	WatchInterval string `yaml:"watch_interval"`
 }
 
 type rawServerConfig struct {
-	Host         string `yaml:"host"`
-	Port         int    `yaml:"port"`
-	ReadTimeout  string `yaml:"read_timeout"`
-	WriteTimeout string `yaml:"write_timeout"`
+	Host           string   `yaml:"host"`
+	Port           int      `yaml:"port"`
+	ReadTimeout    string   `yaml:"read_timeout"`
+	WriteTimeout   string   `yaml:"write_timeout"`
+	TrustedProxies []string `yaml:"trusted_proxies"`
+	ProxyHeader    string   `yaml:"proxy_header"`
+}
+
+type rawAccessLogConfig struct {
+	Enabled      *bool    `yaml:"enabled"`
+	SampleRatio  *float64 `yaml:"sample_ratio"`
+	ExcludePaths []string `yaml:"exclude_paths"`
 }
 
 type rawCORSConfig struct {
@@ -150,18 +474,72 @@ type rawCORSConfig struct {
 }
 
 type rawProviderConfig struct {
-	Default  string                 `yaml:"default"`
-	Stellar  rawProviderItemConfig  `yaml:"stellar"`
-	TinyText rawProviderItemConfig  `yaml:"tinytext"`
+	Default string                  `yaml:"default"`
+	Apps    []rawProviderItemConfig `yaml:"apps"`
 }
 
 type rawProviderItemConfig struct {
-	Enabled     bool   `yaml:"enabled"`
-	Name        string `yaml:"name"`
-	BaseURL     string `yaml:"base_url"`
-	GatewayKey  string `yaml:"gateway_key"`
-	GatewayHead string `yaml:"gateway_header"`
-	Timeout     string `yaml:"timeout"`
+	Enabled             bool                    `yaml:"enabled"`
+	Name                string                  `yaml:"name"`
+	DisplayName         string                  `yaml:"display_name"`
+	Description         string                  `yaml:"description"`
+	Capabilities        []string                `yaml:"capabilities"`
+	BaseURL             string                  `yaml:"base_url"`
+	PathPrefix          string                  `yaml:"path_prefix"`
+	GatewayKey          string                  `yaml:"gateway_key"`
+	GatewayKeySecondary string                  `yaml:"gateway_key_secondary"`
+	GatewayHead         string                  `yaml:"gateway_header"`
+	AuthMode            string                  `yaml:"auth_mode"`
+	Timeout             string                  `yaml:"timeout"`
+	TLS                 rawTLSConfig            `yaml:"tls"`
+	Transport           rawTransportConfig      `yaml:"transport"`
+	Bulkhead            rawBulkheadConfig       `yaml:"bulkhead"`
+	Retry               rawRetryConfig          `yaml:"retry"`
+	CircuitBreaker      rawCircuitBreakerConfig `yaml:"circuit_breaker"`
+	Health              rawHealthCheckConfig    `yaml:"health"`
+}
+
+type rawHealthCheckConfig struct {
+	Enabled  *bool  `yaml:"enabled"`
+	Path     string `yaml:"path"`
+	Interval string `yaml:"interval"`
+	Timeout  string `yaml:"timeout"`
+}
+
+type rawTransportConfig struct {
+	MaxIdleConns          int    `yaml:"max_idle_conns"`
+	MaxIdleConnsPerHost   int    `yaml:"max_idle_conns_per_host"`
+	MaxConnsPerHost       int    `yaml:"max_conns_per_host"`
+	DialTimeout           string `yaml:"dial_timeout"`
+	KeepAlive             string `yaml:"keep_alive"`
+	TLSHandshakeTimeout   string `yaml:"tls_handshake_timeout"`
+	ResponseHeaderTimeout string `yaml:"response_header_timeout"`
+	IdleConnTimeout       string `yaml:"idle_conn_timeout"`
+	DisableKeepAlives     bool   `yaml:"disable_keep_alives"`
+	HTTP2                 *bool  `yaml:"http2"`
+}
+
+type rawBulkheadConfig struct {
+	MaxInFlight *int   `yaml:"max_in_flight"`
+	MaxWait     string `yaml:"max_wait"`
+}
+
+type rawCircuitBreakerConfig struct {
+	Enabled          *bool   `yaml:"enabled"`
+	FailureRatio     float64 `yaml:"failure_ratio"`
+	MinRequests      int     `yaml:"min_requests"`
+	Window           string  `yaml:"window"`
+	CoolDown         string  `yaml:"cool_down"`
+	HalfOpenRequests int     `yaml:"half_open_requests"`
+}
+
+type rawRetryConfig struct {
+	MaxAttempts       int      `yaml:"max_attempts"`
+	InitialBackoff    string   `yaml:"initial_backoff"`
+	MaxBackoff        string   `yaml:"max_backoff"`
+	Jitter            *float64 `yaml:"jitter"`
+	RetryOnStatus     []int    `yaml:"retry_on_status"`
+	IdempotentMethods []string `yaml:"idempotent_methods"`
 }
 
 func defaultRawConfig() rawConfig {
@@ -177,27 +555,195 @@ func defaultRawConfig() rawConfig {
 		},
 		Provider: rawProviderConfig{
 			Default: "",
-			Stellar: rawProviderItemConfig{
-				Enabled:     false,
-				Name:        "stellar",
-				BaseURL:     "http://127.0.0.1:8080/api/v1",
-				GatewayKey:  "",
-				GatewayHead: "X-Gateway-Key",
-				Timeout:     "10s",
-			},
-			TinyText: rawProviderItemConfig{
-				Enabled:     false,
-				Name:        "tinytext",
-				BaseURL:     "http://127.0.0.1:8081/api/v1",
-				GatewayKey:  "",
-				GatewayHead: "X-Gateway-Key",
-				Timeout:     "10s",
-			},
+			Apps:    nil,
 		},
 	}
 }
 
-func resolveConfigPath() (string, error) {
+func buildRetryConfig(raw rawRetryConfig, path string, problems *problemList) RetryConfig {
+	retry := RetryConfig{
+		MaxAttempts:       problems.nonNegative(path+".max_attempts", raw.MaxAttempts, 3),
+		InitialBackoff:    problems.duration(path+".initial_backoff", raw.InitialBackoff, 200*time.Millisecond),
+		MaxBackoff:        problems.duration(path+".max_backoff", raw.MaxBackoff, 2*time.Second),
+		Jitter:            0.2,
+		RetryOnStatus:     raw.RetryOnStatus,
+		IdempotentMethods: make([]string, 0, len(raw.IdempotentMethods)),
+	}
+	if raw.Jitter != nil {
+		if *raw.Jitter < 0 || *raw.Jitter > 1 {
+			problems.add(path+".jitter", "must be between 0 and 1, got %v", *raw.Jitter)
+		} else {
+			retry.Jitter = *raw.Jitter
+		}
+	}
+	if len(retry.RetryOnStatus) == 0 {
+		retry.RetryOnStatus = []int{502, 503, 504}
+	}
+	for i, code := range retry.RetryOnStatus {
+		if code < 100 || code > 599 {
+			problems.add(fmt.Sprintf("%s.retry_on_status[%d]", path, i), "invalid http status %d", code)
+		}
+	}
+	for i, method := range raw.IdempotentMethods {
+		if method = strings.ToUpper(strings.TrimSpace(method)); method != "" {
+			problems.httpMethod(fmt.Sprintf("%s.idempotent_methods[%d]", path, i), method)
+			retry.IdempotentMethods = append(retry.IdempotentMethods, method)
+		}
+	}
+	if len(retry.IdempotentMethods) == 0 {
+		retry.IdempotentMethods = []string{"GET"}
+	}
+	return retry
+}
+
+var defaultAccessLogExcludes = []string{"/livez", "/readyz", "/api/v1/health", "/metrics"}
+
+func buildAccessLogConfig(raw rawAccessLogConfig, problems *problemList) AccessLogConfig {
+	accessLog := AccessLogConfig{
+		Enabled:      raw.Enabled == nil || *raw.Enabled,
+		SampleRatio:  1,
+		ExcludePaths: normalizeList(raw.ExcludePaths),
+	}
+	if raw.ExcludePaths == nil {
+		accessLog.ExcludePaths = append([]string(nil), defaultAccessLogExcludes...)
+	}
+	if raw.SampleRatio != nil {
+		if *raw.SampleRatio < 0 || *raw.SampleRatio > 1 {
+			problems.add("access_log.sample_ratio", "must be between 0 and 1, got %v", *raw.SampleRatio)
+		} else {
+			accessLog.SampleRatio = *raw.SampleRatio
+		}
+	}
+	for i, path := range accessLog.ExcludePaths {
+		if !strings.HasPrefix(path, "/") {
+			problems.add(fmt.Sprintf("access_log.exclude_paths[%d]", i), "must start with /, got %q", path)
+		}
+	}
+	return accessLog
+}
+
+func buildIdentityConfig(raw rawIdentityConfig, problems *problemList) IdentityConfig {
+	identity := IdentityConfig{
+		Mode:        strings.ToLower(normalizeString(raw.Mode, IdentityModeHeader)),
+		Required:    raw.Required,
+		UserHeader:  normalizeString(raw.UserHeader, "X-Gate-User"),
+		RolesHeader: normalizeString(raw.RolesHeader, "X-Gate-Roles"),
+		JWT: GateJWTConfig{
+			Cookie:     normalizeString(raw.JWT.Cookie, "appbox_gate"),
+			Secret:     strings.TrimSpace(raw.JWT.Secret),
+			Issuer:     strings.TrimSpace(raw.JWT.Issuer),
+			Audience:   strings.TrimSpace(raw.JWT.Audience),
+			UserClaim:  normalizeString(raw.JWT.UserClaim, "sub"),
+			RolesClaim: normalizeString(raw.JWT.RolesClaim, "roles"),
+			Leeway:     problems.duration("identity.jwt.leeway", raw.JWT.Leeway, 30*time.Second),
+		},
+	}
+	switch identity.Mode {
+	case IdentityModeHeader:
+	case IdentityModeJWT:
+		if identity.JWT.Secret == "" {
+			problems.add("identity.jwt.secret", "is required when identity.mode is jwt")
+		} else {
+			problems.secretRef("identity.jwt.secret", identity.JWT.Secret)
+		}
+	default:
+		problems.add("identity.mode", "must be one of header, jwt, got %q", raw.Mode)
+	}
+	return identity
+}
+
+func buildTracingConfig(raw rawTracingConfig, problems *problemList) TracingConfig {
+	tracing := TracingConfig{
+		Enabled:     raw.Enabled,
+		Exporter:    strings.ToLower(normalizeString(raw.Exporter, "otlp")),
+		Endpoint:    strings.TrimSpace(raw.Endpoint),
+		Insecure:    raw.Insecure,
+		ServiceName: normalizeString(raw.ServiceName, "appbox_server"),
+		SampleRatio: 1,
+	}
+	if tracing.Exporter != "otlp" && tracing.Exporter != "stdout" {
+		problems.add("tracing.exporter", "must be one of otlp, stdout, got %q", raw.Exporter)
+	}
+	if raw.SampleRatio != nil {
+		if *raw.SampleRatio < 0 || *raw.SampleRatio > 1 {
+			problems.add("tracing.sample_ratio", "must be between 0 and 1, got %v", *raw.SampleRatio)
+		} else {
+			tracing.SampleRatio = *raw.SampleRatio
+		}
+	}
+	return tracing
+}
+
+// buildTransportConfig 未配置的超时保持为 0，由 upstream 沿用默认 transport 的取值；
+// response_header_timeout 默认不限制，整体耗时由 timeout 约束
+func buildTransportConfig(raw rawTransportConfig, path string, problems *problemList) TransportConfig {
+	return TransportConfig{
+		MaxIdleConns:          problems.nonNegative(path+".max_idle_conns", raw.MaxIdleConns, 100),
+		MaxIdleConnsPerHost:   problems.nonNegative(path+".max_idle_conns_per_host", raw.MaxIdleConnsPerHost, 10),
+		MaxConnsPerHost:       problems.nonNegative(path+".max_conns_per_host", raw.MaxConnsPerHost, 0),
+		DialTimeout:           problems.duration(path+".dial_timeout", raw.DialTimeout, 5*time.Second),
+		KeepAlive:             problems.duration(path+".keep_alive", raw.KeepAlive, 30*time.Second),
+		TLSHandshakeTimeout:   problems.duration(path+".tls_handshake_timeout", raw.TLSHandshakeTimeout, 10*time.Second),
+		ResponseHeaderTimeout: problems.optionalDuration(path+".response_header_timeout", raw.ResponseHeaderTimeout, 0),
+		IdleConnTimeout:       problems.duration(path+".idle_conn_timeout", raw.IdleConnTimeout, 90*time.Second),
+		DisableKeepAlives:     raw.DisableKeepAlives,
+		HTTP2:                 raw.HTTP2 == nil || *raw.HTTP2,
+	}
+}
+
+func buildCircuitBreakerConfig(raw rawCircuitBreakerConfig, path string, problems *problemList) CircuitBreakerConfig {
+	breaker := CircuitBreakerConfig{
+		Enabled:          raw.Enabled == nil || *raw.Enabled,
+		FailureRatio:     raw.FailureRatio,
+		MinRequests:      problems.nonNegative(path+".min_requests", raw.MinRequests, 5),
+		Window:           problems.duration(path+".window", raw.Window, 30*time.Second),
+		CoolDown:         problems.duration(path+".cool_down", raw.CoolDown, 15*time.Second),
+		HalfOpenRequests: problems.nonNegative(path+".half_open_requests", raw.HalfOpenRequests, 1),
+	}
+	if breaker.FailureRatio < 0 || breaker.FailureRatio > 1 {
+		problems.add(path+".failure_ratio", "must be between 0 and 1, got %v", breaker.FailureRatio)
+	}
+	if breaker.FailureRatio <= 0 || breaker.FailureRatio > 1 {
+		breaker.FailureRatio = 0.5
+	}
+	return breaker
+}
+
+// normalizeList 去除空白项，返回非 nil 切片
+func normalizeList(raw []string) []string {
+	items := make([]string, 0, len(raw))
+	for _, item := range raw {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// knownCapabilities 与 service 包中的 Capability 常量保持一致
+var knownCapabilities = []string{"users", "planets", "configs"}
+
+func normalizeCapabilities(raw []string) []string {
+	if len(raw) == 0 {
+		return slices.Clone(knownCapabilities)
+	}
+	capabilities := make([]string, 0, len(raw))
+	for _, item := range raw {
+		if item = strings.ToLower(strings.TrimSpace(item)); item != "" {
+			capabilities = append(capabilities, item)
+		}
+	}
+	return capabilities
+}
+
+func resolveConfigPath(explicit string) (string, error) {
+	if path := strings.TrimSpace(explicit); path != "" {
+		return path, nil
+	}
+	if path := strings.TrimSpace(os.Getenv("APPBOX_CONFIG")); path != "" {
+		return path, nil
+	}
+
 	candidates := []string{
 		filepath.Join("config", "config.yaml"),
 		"config.yaml",
@@ -227,18 +773,17 @@ func normalizeInt(value int, fallback int) int {
 	return value
 }
 
-func parseDuration(raw string, fallback time.Duration) time.Duration {
-	value := strings.TrimSpace(raw)
+func normalizeBaseURL(raw string) string {
+	return strings.TrimRight(strings.TrimSpace(raw), "/")
+}
+
+func normalizePathPrefix(raw string, fallback string) string {
+	value := strings.Trim(strings.TrimSpace(raw), "/")
 	if value == "" {
-		return fallback
+		value = strings.Trim(fallback, "/")
 	}
-	parsed, err := time.ParseDuration(value)
-	if err != nil {
-		return fallback
+	if value == "" {
+		return ""
 	}
-	return parsed
-}
-
-func normalizeBaseURL(raw string) string {
-	return strings.TrimRight(strings.TrimSpace(raw), "/")
+	return "/" + value
 }
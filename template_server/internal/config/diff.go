@@ -0,0 +1,76 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+)
+
+var secretFields = map[string]bool{
+	"GatewayKey":          true,
+	"GatewayKeySecondary": true,
+	"Secret":              true,
+}
+
+// Diff 列出两份配置之间的差异，密钥类字段只提示变更不输出明文
+func Diff(oldCfg, newCfg *Config) []string {
+	changes := make([]string, 0)
+	changes = appendStructDiff(changes, "server", oldCfg.Server, newCfg.Server)
+	changes = appendStructDiff(changes, "cors", oldCfg.CORS, newCfg.CORS)
+	changes = appendStructDiff(changes, "reload", oldCfg.Reload, newCfg.Reload)
+	changes = appendStructDiff(changes, "tracing", oldCfg.Tracing, newCfg.Tracing)
+	changes = appendStructDiff(changes, "log", oldCfg.Log, newCfg.Log)
+	changes = appendStructDiff(changes, "access_log", oldCfg.AccessLog, newCfg.AccessLog)
+	changes = appendStructDiff(changes, "audit", oldCfg.Audit, newCfg.Audit)
+	changes = appendStructDiff(changes, "identity", oldCfg.Identity, newCfg.Identity)
+	changes = appendStructDiff(changes, "rbac", oldCfg.RBAC, newCfg.RBAC)
+	changes = appendStructDiff(changes, "api_tokens", oldCfg.APITokens, newCfg.APITokens)
+	changes = appendStructDiff(changes, "rate_limit", oldCfg.RateLimit, newCfg.RateLimit)
+	changes = appendStructDiff(changes, "search", oldCfg.Search, newCfg.Search)
+	if oldCfg.Provider.Default != newCfg.Provider.Default {
+		changes = append(changes, fmt.Sprintf("provider.default: %q -> %q", oldCfg.Provider.Default, newCfg.Provider.Default))
+	}
+
+	oldApps := make(map[string]AppProviderConfig, len(oldCfg.Provider.Apps))
+	for _, app := range oldCfg.Provider.Apps {
+		oldApps[app.Name] = app
+	}
+	newApps := make(map[string]bool, len(newCfg.Provider.Apps))
+	for _, app := range newCfg.Provider.Apps {
+		newApps[app.Name] = true
+		previous, ok := oldApps[app.Name]
+		if !ok {
+			changes = append(changes, fmt.Sprintf("provider.apps[%s]: added", app.Name))
+			continue
+		}
+		changes = appendStructDiff(changes, "provider.apps["+app.Name+"]", previous, app)
+	}
+	for _, app := range oldCfg.Provider.Apps {
+		if !newApps[app.Name] {
+			changes = append(changes, fmt.Sprintf("provider.apps[%s]: removed", app.Name))
+		}
+	}
+	return changes
+}
+
+func appendStructDiff(changes []string, prefix string, oldValue, newValue interface{}) []string {
+	oldRV := reflect.ValueOf(oldValue)
+	newRV := reflect.ValueOf(newValue)
+	for i := 0; i < oldRV.NumField(); i++ {
+		field := oldRV.Type().Field(i)
+		before := oldRV.Field(i).Interface()
+		after := newRV.Field(i).Interface()
+		if reflect.DeepEqual(before, after) {
+			continue
+		}
+		path := prefix + "." + field.Name
+		switch {
+		case secretFields[field.Name]:
+			changes = append(changes, path+": changed")
+		case field.Type.Kind() == reflect.Struct:
+			changes = appendStructDiff(changes, path, before, after)
+		default:
+			changes = append(changes, fmt.Sprintf("%s: %v -> %v", path, before, after))
+		}
+	}
+	return changes
+}
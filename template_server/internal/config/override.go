@@ -0,0 +1,136 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const envPrefix = "APPBOX_"
+
+// applyOverrides 遍历 rawConfig 的全部叶子字段，依次用环境变量与命令行覆盖项改写 YAML 解析结果。
+// 字段路径取 yaml tag，provider.apps 列表项以 name 作为路径段，例如：
+//
+//	provider.stellar.gateway_key <-> APPBOX_PROVIDER_STELLAR_GATEWAY_KEY
+func applyOverrides(raw *rawConfig, lookupEnv func(string) (string, bool), overrides map[string]string, problems *problemList) {
+	used := make(map[string]bool, len(overrides))
+	walkOverrides(reflect.ValueOf(raw).Elem(), nil, func(path []string, field reflect.Value) {
+		dotted := strings.Join(path, ".")
+		if value, ok := overrides[dotted]; ok {
+			used[dotted] = true
+			if err := setOverride(field, "--set "+dotted, value); err != nil {
+				problems.add(dotted, "%v", err)
+			}
+			return
+		}
+		if value, ok := lookupEnv(envName(path)); ok {
+			if err := setOverride(field, envName(path), value); err != nil {
+				problems.add(dotted, "%v", err)
+			}
+		}
+	})
+
+	unknown := make([]string, 0)
+	for key := range overrides {
+		if !used[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	for _, key := range unknown {
+		problems.add(key, "unknown config override (--set)")
+	}
+}
+
+func walkOverrides(v reflect.Value, path []string, visit func(path []string, field reflect.Value)) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		tag := strings.Split(t.Field(i).Tag.Get("yaml"), ",")[0]
+		if tag == "" || tag == "-" {
+			continue
+		}
+		field := v.Field(i)
+		fieldPath := append(append([]string(nil), path...), tag)
+
+		switch {
+		case field.Kind() == reflect.Struct:
+			walkOverrides(field, fieldPath, visit)
+		case field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.Struct:
+			for j := 0; j < field.Len(); j++ {
+				item := field.Index(j)
+				name := item.FieldByName("Name")
+				if !name.IsValid() || strings.TrimSpace(name.String()) == "" {
+					continue
+				}
+				itemPath := append(append([]string(nil), path...), strings.TrimSpace(name.String()))
+				walkOverrides(item, itemPath, visit)
+			}
+		default:
+			visit(fieldPath, field)
+		}
+	}
+}
+
+func envName(path []string) string {
+	name := strings.ToUpper(strings.Join(path, "_"))
+	return envPrefix + strings.Map(func(r rune) rune {
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+func setOverride(field reflect.Value, source, value string) error {
+	if field.Kind() == reflect.Ptr {
+		elem := reflect.New(field.Type().Elem())
+		if err := setOverride(elem.Elem(), source, value); err != nil {
+			return err
+		}
+		field.Set(elem)
+		return nil
+	}
+
+	value = strings.TrimSpace(value)
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Int:
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid integer for %s: %q", source, value)
+		}
+		field.SetInt(int64(parsed))
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid boolean for %s: %q", source, value)
+		}
+		field.SetBool(parsed)
+	case reflect.Float64:
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number for %s: %q", source, value)
+		}
+		field.SetFloat(parsed)
+	case reflect.Slice:
+		items := make([]string, 0)
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		slice := reflect.MakeSlice(field.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := setOverride(slice.Index(i), source, item); err != nil {
+				return err
+			}
+		}
+		field.Set(slice)
+	default:
+		return fmt.Errorf("unsupported override type for %s", source)
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	RateLimitClassRead  = "read"
+	RateLimitClassWrite = "write"
+	RateLimitClassAll   = "all"
+
+	RateLimitScopeOperator = "operator"
+	RateLimitScopeProvider = "provider"
+)
+
+// RateLimitConfig 为令牌桶限流规则；请求需同时通过所有匹配规则
+type RateLimitConfig struct {
+	Enabled bool
+	Rules   []RateLimitRule
+}
+
+// RateLimitRule 每 Per 补充 Rate 个令牌、桶容量为 Burst；Scope 为 operator 时每个操作人（含 API token）独立计数，
+// 为 provider 时同一 provider 的全部操作人共享；Operators 为空表示匹配全部操作人
+type RateLimitRule struct {
+	Name      string
+	Operators []string
+	Providers []string
+	Class     string
+	Scope     string
+	Rate      float64
+	Per       time.Duration
+	Burst     int
+}
+
+type rawRateLimitConfig struct {
+	Enabled bool               `yaml:"enabled"`
+	Rules   []rawRateLimitRule `yaml:"rules"`
+}
+
+type rawRateLimitRule struct {
+	Name      string   `yaml:"name"`
+	Operators []string `yaml:"operators"`
+	Providers []string `yaml:"providers"`
+	Class     string   `yaml:"class"`
+	Scope     string   `yaml:"scope"`
+	Rate      float64  `yaml:"rate"`
+	Per       string   `yaml:"per"`
+	Burst     int      `yaml:"burst"`
+}
+
+func buildRateLimitConfig(raw rawRateLimitConfig, declared map[string]string, problems *problemList) RateLimitConfig {
+	rateLimit := RateLimitConfig{Enabled: raw.Enabled, Rules: make([]RateLimitRule, 0, len(raw.Rules))}
+	names := make(map[string]bool, len(raw.Rules))
+	for i, item := range raw.Rules {
+		path := fmt.Sprintf("rate_limit.rules[%d]", i)
+		rule := RateLimitRule{
+			Name:      strings.TrimSpace(item.Name),
+			Operators: normalizeList(item.Operators),
+			Providers: normalizeList(item.Providers),
+			Class:     strings.ToLower(normalizeString(item.Class, RateLimitClassAll)),
+			Scope:     strings.ToLower(normalizeString(item.Scope, RateLimitScopeOperator)),
+			Rate:      item.Rate,
+			Per:       problems.duration(path+".per", item.Per, time.Second),
+			Burst:     problems.nonNegative(path+".burst", item.Burst, 0),
+		}
+		if len(rule.Providers) == 0 {
+			rule.Providers = []string{RBACWildcard}
+		}
+
+		switch {
+		case rule.Name == "":
+			problems.add(path+".name", "is required")
+		case names[rule.Name]:
+			problems.add(path+".name", "duplicate rule %q", rule.Name)
+		default:
+			names[rule.Name] = true
+		}
+		for j, provider := range rule.Providers {
+			if _, ok := declared[provider]; !ok && provider != RBACWildcard {
+				problems.add(fmt.Sprintf("%s.providers[%d]", path, j), "unknown provider %q", provider)
+			}
+		}
+		if rule.Class != RateLimitClassRead && rule.Class != RateLimitClassWrite && rule.Class != RateLimitClassAll {
+			problems.add(path+".class", "must be one of read, write, all, got %q", item.Class)
+		}
+		if rule.Scope != RateLimitScopeOperator && rule.Scope != RateLimitScopeProvider {
+			problems.add(path+".scope", "must be one of operator, provider, got %q", item.Scope)
+		}
+		if rule.Rate <= 0 {
+			problems.add(path+".rate", "must be positive, got %v", item.Rate)
+		}
+		if rule.Burst == 0 {
+			rule.Burst = max(1, int(rule.Rate))
+		}
+		rateLimit.Rules = append(rateLimit.Rules, rule)
+	}
+	return rateLimit
+}
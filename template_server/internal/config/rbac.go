@@ -0,0 +1,112 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RBACWildcard 在 providers 或 operations 中表示全部
+const RBACWildcard = "*"
+
+// rbacOperations 为可授权的操作名，需与 rbac 包中的操作常量保持一致
+var rbacOperations = map[string]bool{
+	"ListUsers":       true,
+	"ListUserPlanets": true,
+	"UpdateUser":      true,
+	"DeleteUser":      true,
+	"ListConfigs":     true,
+	"UpsertConfig":    true,
+	"DeleteConfig":    true,
+	"ListAudit":       true,
+	"ManageTokens":    true,
+}
+
+// RBACConfig 将角色映射到允许的 (provider, operation) 组合；未启用时不做权限校验。
+// 未识别身份的请求使用 AnonymousRoles
+type RBACConfig struct {
+	Enabled        bool
+	AnonymousRoles []string
+	Roles          []RoleConfig
+}
+
+type RoleConfig struct {
+	Name        string
+	Permissions []PermissionConfig
+}
+
+type PermissionConfig struct {
+	Providers  []string
+	Operations []string
+}
+
+type rawRBACConfig struct {
+	Enabled        bool            `yaml:"enabled"`
+	AnonymousRoles []string        `yaml:"anonymous_roles"`
+	Roles          []rawRoleConfig `yaml:"roles"`
+}
+
+type rawRoleConfig struct {
+	Name        string                `yaml:"name"`
+	Permissions []rawPermissionConfig `yaml:"permissions"`
+}
+
+type rawPermissionConfig struct {
+	Providers  []string `yaml:"providers"`
+	Operations []string `yaml:"operations"`
+}
+
+// buildRBACConfig 解析并校验角色定义，providers 必须为 provider.apps 中声明过的名称或 *
+func buildRBACConfig(raw rawRBACConfig, declared map[string]string, problems *problemList) RBACConfig {
+	rbac := RBACConfig{
+		Enabled:        raw.Enabled,
+		AnonymousRoles: normalizeList(raw.AnonymousRoles),
+		Roles:          make([]RoleConfig, 0, len(raw.Roles)),
+	}
+
+	defined := make(map[string]bool, len(raw.Roles))
+	for i, item := range raw.Roles {
+		path := fmt.Sprintf("rbac.roles[%d]", i)
+		role := RoleConfig{Name: strings.TrimSpace(item.Name), Permissions: make([]PermissionConfig, 0, len(item.Permissions))}
+		switch {
+		case role.Name == "":
+			problems.add(path+".name", "is required")
+		case defined[role.Name]:
+			problems.add(path+".name", "duplicate role %q", role.Name)
+		default:
+			defined[role.Name] = true
+		}
+
+		for j, rawPermission := range item.Permissions {
+			permPath := fmt.Sprintf("%s.permissions[%d]", path, j)
+			permission := PermissionConfig{
+				Providers:  normalizeList(rawPermission.Providers),
+				Operations: normalizeList(rawPermission.Operations),
+			}
+			if len(permission.Providers) == 0 {
+				problems.add(permPath+".providers", "is required")
+			}
+			for k, provider := range permission.Providers {
+				if _, ok := declared[provider]; !ok && provider != RBACWildcard {
+					problems.add(fmt.Sprintf("%s.providers[%d]", permPath, k), "unknown provider %q", provider)
+				}
+			}
+			if len(permission.Operations) == 0 {
+				problems.add(permPath+".operations", "is required")
+			}
+			for k, operation := range permission.Operations {
+				if !rbacOperations[operation] && operation != RBACWildcard {
+					problems.add(fmt.Sprintf("%s.operations[%d]", permPath, k), "unknown operation %q", operation)
+				}
+			}
+			role.Permissions = append(role.Permissions, permission)
+		}
+		rbac.Roles = append(rbac.Roles, role)
+	}
+
+	for i, role := range rbac.AnonymousRoles {
+		if !defined[role] {
+			problems.add(fmt.Sprintf("rbac.anonymous_roles[%d]", i), "undefined role %q", role)
+		}
+	}
+	return rbac
+}
@@ -0,0 +1,99 @@
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// TLSConfig 为上游连接的 TLS 设置：CAFile 追加到系统根证书，CertFile/KeyFile 用于双向 TLS
+type TLSConfig struct {
+	CAFile     string
+	CertFile   string
+	KeyFile    string
+	ServerName string
+	MinVersion string
+}
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// Enabled 判断是否声明了自定义 TLS 设置，未声明时沿用 Go 默认 transport 行为
+func (c TLSConfig) Enabled() bool {
+	return c != TLSConfig{}
+}
+
+// Build 读取证书文件并生成 tls.Config；配置加载阶段会调用一次以提前发现证书问题，
+// 返回的错误汇总全部问题（errors.Join）
+func (c TLSConfig) Build() (*tls.Config, error) {
+	if !c.Enabled() {
+		return nil, nil
+	}
+
+	var errs []error
+	tlsCfg := &tls.Config{
+		ServerName: c.ServerName,
+		MinVersion: tls.VersionTLS12,
+	}
+	if c.MinVersion != "" {
+		version, ok := tlsVersions[c.MinVersion]
+		if ok {
+			tlsCfg.MinVersion = version
+		} else {
+			errs = append(errs, fmt.Errorf("unsupported min_version %q, expected one of 1.0, 1.1, 1.2, 1.3", c.MinVersion))
+		}
+	}
+
+	if c.CAFile != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if pem, err := os.ReadFile(c.CAFile); err != nil {
+			errs = append(errs, fmt.Errorf("read ca_file failed: %w", err))
+		} else if !pool.AppendCertsFromPEM(pem) {
+			errs = append(errs, fmt.Errorf("ca_file %s contains no PEM certificates", c.CAFile))
+		}
+		tlsCfg.RootCAs = pool
+	}
+
+	if (c.CertFile == "") != (c.KeyFile == "") {
+		errs = append(errs, fmt.Errorf("cert_file and key_file must be set together"))
+	} else if c.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("load client certificate failed: %w", err))
+		}
+		tlsCfg.Certificates = []tls.Certificate{cert}
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return tlsCfg, nil
+}
+
+type rawTLSConfig struct {
+	CAFile     string `yaml:"ca_file"`
+	CertFile   string `yaml:"cert_file"`
+	KeyFile    string `yaml:"key_file"`
+	ServerName string `yaml:"server_name"`
+	MinVersion string `yaml:"min_version"`
+}
+
+func buildTLSConfig(raw rawTLSConfig) TLSConfig {
+	return TLSConfig{
+		CAFile:     strings.TrimSpace(raw.CAFile),
+		CertFile:   strings.TrimSpace(raw.CertFile),
+		KeyFile:    strings.TrimSpace(raw.KeyFile),
+		ServerName: strings.TrimSpace(raw.ServerName),
+		MinVersion: strings.TrimSpace(raw.MinVersion),
+	}
+}
@@ -0,0 +1,209 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"appbox/appbox_server/internal/secret"
+)
+
+type Problem struct {
+	Path    string
+	Message string
+}
+
+// ValidationError 汇总一次加载中发现的全部配置问题，每项带 YAML 路径
+type ValidationError struct {
+	File     string
+	Problems []Problem
+}
+
+func (e *ValidationError) Error() string {
+	lines := make([]string, 0, len(e.Problems)+1)
+	lines = append(lines, fmt.Sprintf("invalid config %q (%d problems):", e.File, len(e.Problems)))
+	for _, problem := range e.Problems {
+		lines = append(lines, fmt.Sprintf("  - %s: %s", problem.Path, problem.Message))
+	}
+	return strings.Join(lines, "\n")
+}
+
+type problemList []Problem
+
+func (p *problemList) add(path, format string, args ...interface{}) {
+	*p = append(*p, Problem{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// duration 解析时长，空值使用 fallback，格式错误或非正数记录问题并返回 fallback
+func (p *problemList) duration(path, raw string, fallback time.Duration) time.Duration {
+	value := strings.TrimSpace(raw)
+	if value == "" {
+		return fallback
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		p.add(path, "invalid duration %q (expected e.g. 500ms, 10s, 1m)", value)
+		return fallback
+	}
+	if parsed <= 0 {
+		p.add(path, "duration must be positive, got %q", value)
+		return fallback
+	}
+	return parsed
+}
+
+// optionalDuration 与 duration 相同，但允许 0，用于默认关闭、0 表示不启用的时长
+func (p *problemList) optionalDuration(path, raw string, fallback time.Duration) time.Duration {
+	value := strings.TrimSpace(raw)
+	if value == "" {
+		return fallback
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		p.add(path, "invalid duration %q (expected e.g. 500ms, 10s, 1m)", value)
+		return fallback
+	}
+	if parsed < 0 {
+		p.add(path, "duration must not be negative, got %q", value)
+		return fallback
+	}
+	return parsed
+}
+
+// limit 解析可显式设为 0（不限制）的上限，未配置时使用 fallback
+func (p *problemList) limit(path string, value *int, fallback int) int {
+	if value == nil {
+		return fallback
+	}
+	if *value < 0 {
+		p.add(path, "must not be negative, got %d", *value)
+		return fallback
+	}
+	return *value
+}
+
+func (p *problemList) nonNegative(path string, value int, fallback int) int {
+	if value < 0 {
+		p.add(path, "must not be negative, got %d", value)
+		return fallback
+	}
+	return normalizeInt(value, fallback)
+}
+
+func (p *problemList) baseURL(path, value string) {
+	parsed, err := url.Parse(value)
+	if err != nil {
+		p.add(path, "malformed url %q: %v", value, err)
+		return
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		p.add(path, "url %q must use http or https scheme", value)
+		return
+	}
+	if parsed.Host == "" {
+		p.add(path, "url %q has no host", value)
+	}
+	if parsed.RawQuery != "" || parsed.Fragment != "" {
+		p.add(path, "url %q must not contain query or fragment", value)
+	}
+}
+
+func (p *problemList) secretRef(path, ref string) {
+	if _, err := secret.New(ref).Value(); err != nil {
+		p.add(path, "cannot resolve %s: %v", secret.Describe(ref), err)
+	}
+}
+
+func (p *problemList) tls(path, baseURL string, cfg TLSConfig) {
+	if !cfg.Enabled() {
+		return
+	}
+	if strings.HasPrefix(strings.ToLower(baseURL), "http://") {
+		p.add(path, "tls settings require an https base_url")
+	}
+	_, err := cfg.Build()
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, item := range joined.Unwrap() {
+			p.add(path, "%v", item)
+		}
+	} else if err != nil {
+		p.add(path, "%v", err)
+	}
+}
+
+func (p *problemList) ipOrCIDR(path, value string) {
+	if strings.Contains(value, "/") {
+		if _, _, err := net.ParseCIDR(value); err != nil {
+			p.add(path, "invalid CIDR %q", value)
+		}
+		return
+	}
+	if net.ParseIP(value) == nil {
+		p.add(path, "invalid IP %q", value)
+	}
+}
+
+func (p *problemList) httpMethod(path, method string) {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodOptions:
+	default:
+		p.add(path, "unknown http method %q", method)
+	}
+}
+
+// checkUnknownFields 对照 raw 结构体的 yaml tag 遍历 YAML 节点树，记录拼写错误或不存在的字段
+func (p *problemList) checkUnknownFields(node *yaml.Node, t reflect.Type, path string) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			p.checkUnknownFields(child, t, path)
+		}
+	case yaml.MappingNode:
+		if t.Kind() != reflect.Struct {
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i].Value
+			fieldPath := joinPath(path, key)
+			field, ok := fieldByYAMLTag(t, key)
+			if !ok {
+				p.add(fieldPath, "unknown field (line %d)", node.Content[i].Line)
+				continue
+			}
+			p.checkUnknownFields(node.Content[i+1], field.Type, fieldPath)
+		}
+	case yaml.SequenceNode:
+		if t.Kind() != reflect.Slice {
+			return
+		}
+		for i, child := range node.Content {
+			p.checkUnknownFields(child, t.Elem(), fmt.Sprintf("%s[%d]", path, i))
+		}
+	}
+}
+
+func fieldByYAMLTag(t reflect.Type, key string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if strings.Split(field.Tag.Get("yaml"), ",")[0] == key {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+func joinPath(parent, key string) string {
+	if parent == "" {
+		return key
+	}
+	return parent + "." + key
+}
@@ -0,0 +1,31 @@
+package config
+
+import (
+	"context"
+	"os"
+	"time"
+)
+
+// Watch 按 interval 轮询配置文件的修改时间与大小，发生变化时调用 onChange，直到 ctx 结束
+func Watch(ctx context.Context, path string, interval time.Duration, onChange func()) {
+	last, _ := os.Stat(path)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			current, err := os.Stat(path)
+			if err != nil {
+				continue
+			}
+			if last != nil && current.ModTime().Equal(last.ModTime()) && current.Size() == last.Size() {
+				continue
+			}
+			last = current
+			onChange()
+		}
+	}
+}
@@ -2,6 +2,7 @@ package dto
 
 // AdminUserUpdateRequest 管理端更新用户
 // 字段与前端保持一致
+//
 //nolint:tagliatelle
 type AdminUserUpdateRequest struct {
 	Username              *string `json:"username"`
@@ -61,3 +62,23 @@ type AppConfigUpsertRequest struct {
 	ValueType   string `json:"valueType"`
 	Description string `json:"description"`
 }
+
+type ProviderItem struct {
+	Key          string         `json:"key"`
+	DisplayName  string         `json:"displayName"`
+	Description  string         `json:"description"`
+	IsDefault    bool           `json:"isDefault"`
+	BaseURLHost  string         `json:"baseUrlHost"`
+	Capabilities []string       `json:"capabilities"`
+	CircuitState string         `json:"circuitState"`
+	Health       ProviderHealth `json:"health"`
+	Version      string         `json:"version"`
+}
+
+type ProviderHealth struct {
+	Status    string `json:"status"`
+	LatencyMs int64  `json:"latencyMs"`
+	LastError string `json:"lastError,omitempty"`
+	Version   string `json:"version,omitempty"`
+	CheckedAt int64  `json:"checkedAt,omitempty"`
+}
@@ -0,0 +1,31 @@
+package dto
+
+// APITokenCreateRequest 创建 API token；ExpiresIn（如 720h）与 ExpiresAt（RFC3339）二选一，均为空时使用默认有效期
+type APITokenCreateRequest struct {
+	Name       string   `json:"name"`
+	Providers  []string `json:"providers"`
+	Operations []string `json:"operations"`
+	ExpiresIn  string   `json:"expiresIn"`
+	ExpiresAt  string   `json:"expiresAt"`
+}
+
+// APIToken 为 token 元数据，不含明文与哈希；时间均为 unix 毫秒，0 表示未发生
+type APIToken struct {
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	Prefix     string   `json:"prefix"`
+	Providers  []string `json:"providers"`
+	Operations []string `json:"operations"`
+	CreatedBy  string   `json:"createdBy"`
+	CreatedAt  int64    `json:"createdAt"`
+	ExpiresAt  int64    `json:"expiresAt"`
+	RevokedAt  int64    `json:"revokedAt,omitempty"`
+	LastUsedAt int64    `json:"lastUsedAt,omitempty"`
+	Status     string   `json:"status"`
+}
+
+// APITokenCreated 为创建结果，Token 明文只在此时返回一次
+type APITokenCreated struct {
+	APIToken
+	Token string `json:"token"`
+}
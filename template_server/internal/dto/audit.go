@@ -0,0 +1,20 @@
+package dto
+
+import "encoding/json"
+
+// AuditEntry 一次变更类管理操作的审计记录
+type AuditEntry struct {
+	ID         string          `json:"id"`
+	Time       int64           `json:"time"`
+	Operator   string          `json:"operator"`
+	ClientIP   string          `json:"clientIp"`
+	RequestID  string          `json:"requestId"`
+	Provider   string          `json:"provider"`
+	Operation  string          `json:"operation"`
+	TargetID   string          `json:"targetId"`
+	Payload    json.RawMessage `json:"payload,omitempty"`
+	Result     string          `json:"result"`
+	StatusCode int             `json:"statusCode"`
+	Error      string          `json:"error,omitempty"`
+	LatencyMs  int64           `json:"latencyMs"`
+}
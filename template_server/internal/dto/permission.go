@@ -0,0 +1,16 @@
+package dto
+
+// MissingPermission 为 403 响应中缺少的权限，网关操作的 provider 为空
+type MissingPermission struct {
+	Provider  string `json:"provider,omitempty"`
+	Operation string `json:"operation"`
+}
+
+// OperatorPermissions 为当前操作人可执行的操作，供前端按权限隐藏按钮
+type OperatorPermissions struct {
+	Operator    string              `json:"operator"`
+	Roles       []string            `json:"roles"`
+	RBACEnabled bool                `json:"rbacEnabled"`
+	Providers   map[string][]string `json:"providers"`
+	Gateway     []string            `json:"gateway"`
+}
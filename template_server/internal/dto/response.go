@@ -5,6 +5,7 @@ type Response struct {
 	Timestamp int64       `json:"timestamp"`
 	Msg       string      `json:"msg"`
 	Data      interface{} `json:"data,omitempty"`
+	RequestID string      `json:"requestId,omitempty"`
 }
 
 type PaginationResponse[T any] struct {
@@ -0,0 +1,34 @@
+package dto
+
+// 跨 provider 搜索中单个 provider 的结果状态
+const (
+	SearchStatusOK      = "ok"
+	SearchStatusFailed  = "failed"
+	SearchStatusSkipped = "skipped"
+)
+
+// UserSearchResponse 为跨 provider 用户搜索结果：Items 按 provider 顺序合并，Partial 表示有 provider 未返回结果
+type UserSearchResponse struct {
+	Keyword   string                `json:"keyword"`
+	Items     []UserSearchItem      `json:"items"`
+	Providers []ProviderSearchState `json:"providers"`
+	Partial   bool                  `json:"partial"`
+}
+
+// UserSearchItem 为带来源 provider 的用户
+type UserSearchItem struct {
+	Provider string `json:"provider"`
+	User
+}
+
+// ProviderSearchState 为单个 provider 的搜索结果；Result 与指标的 result 标签取值一致（如 timeout、connection）
+type ProviderSearchState struct {
+	Provider   string `json:"provider"`
+	Status     string `json:"status"`
+	Total      int64  `json:"total"`
+	Count      int    `json:"count"`
+	LatencyMs  int64  `json:"latencyMs"`
+	Result     string `json:"result,omitempty"`
+	StatusCode int    `json:"statusCode,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
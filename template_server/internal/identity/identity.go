@@ -0,0 +1,203 @@
+// Package identity 从 nginx gate 传递的可信请求头或 gate 签发的 JWT cookie 中识别管理端操作人。
+package identity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"appbox/appbox_server/internal/apitoken"
+	"appbox/appbox_server/internal/config"
+	"appbox/appbox_server/internal/requestctx"
+	"appbox/appbox_server/internal/secret"
+)
+
+const (
+	SourceHeader = "header"
+	SourceJWT    = "jwt"
+	SourceToken  = "token"
+
+	// TokenOperatorPrefix 为 API token 操作人标识的前缀，如 token:deploy-bot
+	TokenOperatorPrefix = "token:"
+)
+
+var (
+	ErrUntrustedSource = errors.New("identity headers from untrusted source")
+	ErrInvalidToken    = errors.New("invalid token")
+	ErrTokenExpired    = errors.New("token expired")
+)
+
+// Request 为身份识别所需的请求信息，与具体 HTTP 框架解耦
+type Request struct {
+	// RemoteIP 为 TCP 连接的来源地址，不能取自代理头
+	RemoteIP net.IP
+	Header   func(name string) string
+	Cookie   func(name string) string
+}
+
+type Extractor struct {
+	cfg     config.IdentityConfig
+	trusted []*net.IPNet
+	secret  secret.Source
+	tokens  apitoken.Store
+	now     func() time.Time
+}
+
+// NewExtractor 按配置创建身份识别器；trustedProxies 为空时仅信任本机回环地址发来的身份请求头，
+// tokens 为 nil 表示未启用 API token，此时携带 Bearer token 的请求会被拒绝
+func NewExtractor(cfg config.IdentityConfig, trustedProxies []string, tokens apitoken.Store) *Extractor {
+	extractor := &Extractor{cfg: cfg, tokens: tokens, now: time.Now}
+	for _, proxy := range trustedProxies {
+		if network := parseNetwork(proxy); network != nil {
+			extractor.trusted = append(extractor.trusted, network)
+		}
+	}
+	if cfg.Mode == config.IdentityModeJWT {
+		extractor.secret = secret.New(cfg.JWT.Secret)
+	}
+	return extractor
+}
+
+// Extract 返回请求携带的操作人；携带 Authorization: Bearer 时优先按 API token 识别，
+// 未携带任何身份信息时 ok 为 false，身份信息不可信（伪造的请求头、签名或有效期不符的 token）时返回错误
+func (e *Extractor) Extract(ctx context.Context, r Request) (requestctx.Operator, bool, error) {
+	if bearer, ok := bearerToken(r.Header("Authorization")); ok {
+		return e.extractAPIToken(ctx, bearer)
+	}
+	if e.cfg.Mode == config.IdentityModeJWT {
+		return e.extractJWT(r)
+	}
+	return e.extractHeader(r)
+}
+
+func (e *Extractor) extractHeader(r Request) (requestctx.Operator, bool, error) {
+	user := strings.TrimSpace(r.Header(e.cfg.UserHeader))
+	roles := strings.TrimSpace(r.Header(e.cfg.RolesHeader))
+	if user == "" && roles == "" {
+		return requestctx.Operator{}, false, nil
+	}
+	if !e.trustedSource(r.RemoteIP) {
+		return requestctx.Operator{}, false, ErrUntrustedSource
+	}
+	if user == "" {
+		return requestctx.Operator{}, false, nil
+	}
+	return requestctx.Operator{ID: user, Roles: splitRoles(roles), Source: SourceHeader}, true, nil
+}
+
+func (e *Extractor) extractJWT(r Request) (requestctx.Operator, bool, error) {
+	token := strings.TrimSpace(r.Cookie(e.cfg.JWT.Cookie))
+	if token == "" {
+		return requestctx.Operator{}, false, nil
+	}
+	key, err := e.secret.Value()
+	if err != nil {
+		return requestctx.Operator{}, false, err
+	}
+	claims, err := verifyHS256(token, []byte(key))
+	if err != nil {
+		return requestctx.Operator{}, false, err
+	}
+	if err := e.validateClaims(claims); err != nil {
+		return requestctx.Operator{}, false, err
+	}
+
+	user, _ := claims[e.cfg.JWT.UserClaim].(string)
+	user = strings.TrimSpace(user)
+	if user == "" {
+		return requestctx.Operator{}, false, ErrInvalidToken
+	}
+	return requestctx.Operator{ID: user, Roles: claimRoles(claims[e.cfg.JWT.RolesClaim]), Source: SourceJWT}, true, nil
+}
+
+func (e *Extractor) extractAPIToken(ctx context.Context, bearer string) (requestctx.Operator, bool, error) {
+	if e.tokens == nil {
+		return requestctx.Operator{}, false, fmt.Errorf("%w: api tokens are not enabled", ErrInvalidToken)
+	}
+	token, err := e.tokens.Authenticate(ctx, bearer, e.now())
+	switch {
+	case errors.Is(err, apitoken.ErrExpired):
+		return requestctx.Operator{}, false, fmt.Errorf("%w: %w", ErrTokenExpired, err)
+	case errors.Is(err, apitoken.ErrInvalid), errors.Is(err, apitoken.ErrRevoked):
+		return requestctx.Operator{}, false, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	case err != nil:
+		return requestctx.Operator{}, false, err
+	}
+	return requestctx.Operator{
+		ID:     TokenOperatorPrefix + token.Name,
+		Source: SourceToken,
+		Scope:  &requestctx.Scope{Providers: token.Providers, Operations: token.Operations},
+	}, true, nil
+}
+
+func bearerToken(header string) (string, bool) {
+	scheme, token, ok := strings.Cut(strings.TrimSpace(header), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+func (e *Extractor) trustedSource(ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	if len(e.trusted) == 0 {
+		return ip.IsLoopback()
+	}
+	for _, network := range e.trusted {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseNetwork(value string) *net.IPNet {
+	if strings.Contains(value, "/") {
+		_, network, err := net.ParseCIDR(value)
+		if err != nil {
+			return nil
+		}
+		return network
+	}
+	ip := net.ParseIP(value)
+	if ip == nil {
+		return nil
+	}
+	bits := 8 * net.IPv6len
+	if ip4 := ip.To4(); ip4 != nil {
+		ip, bits = ip4, 8*net.IPv4len
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}
+}
+
+// splitRoles 解析逗号或空格分隔的角色列表
+func splitRoles(raw string) []string {
+	fields := strings.FieldsFunc(raw, func(r rune) bool { return r == ',' || r == ' ' })
+	if len(fields) == 0 {
+		return nil
+	}
+	return fields
+}
+
+func claimRoles(value interface{}) []string {
+	switch typed := value.(type) {
+	case string:
+		return splitRoles(typed)
+	case []interface{}:
+		roles := make([]string, 0, len(typed))
+		for _, item := range typed {
+			if role, ok := item.(string); ok && strings.TrimSpace(role) != "" {
+				roles = append(roles, strings.TrimSpace(role))
+			}
+		}
+		return roles
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,107 @@
+// Package metrics 定义网关暴露的 Prometheus 指标，统一注册在独立的 Registry 上，由 /metrics 输出。
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "appbox"
+
+var Registry = prometheus.NewRegistry()
+
+var (
+	HTTPRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Inbound HTTP requests by method, route template and status code.",
+	}, []string{"method", "route", "status"})
+
+	HTTPDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Inbound HTTP request latency by method and route template.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	HTTPInFlight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "http_requests_in_flight",
+		Help:      "Inbound HTTP requests currently being served.",
+	})
+
+	ProviderCalls = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "provider_calls_total",
+		Help:      "AdminProvider calls by provider, operation and result class (including retries).",
+	}, []string{"provider", "operation", "result"})
+
+	ProviderDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "provider_call_duration_seconds",
+		Help:      "AdminProvider call latency by provider and operation (including retries).",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"provider", "operation"})
+
+	UpstreamAttempts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "upstream_attempts_total",
+		Help:      "Individual upstream HTTP attempts by provider, method and result class.",
+	}, []string{"provider", "method", "result"})
+
+	UpstreamDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "upstream_attempt_duration_seconds",
+		Help:      "Individual upstream HTTP attempt latency by provider and method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"provider", "method"})
+
+	UpstreamInFlight = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "upstream_requests_in_flight",
+		Help:      "Upstream calls currently in flight per provider.",
+	}, []string{"provider"})
+
+	RateLimitDecisions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rate_limit_decisions_total",
+		Help:      "Rate limit decisions per rule, provider and operation class (allowed, limited).",
+	}, []string{"rule", "provider", "class", "result"})
+
+	ConfigReloads = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "config_reloads_total",
+		Help:      "Config reload attempts by result (applied, unchanged, rejected).",
+	}, []string{"result"})
+
+	ConfigLastReload = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "config_last_reload_success_timestamp_seconds",
+		Help:      "Unix time of the last successfully applied config.",
+	})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequests,
+		HTTPDuration,
+		HTTPInFlight,
+		ProviderCalls,
+		ProviderDuration,
+		UpstreamAttempts,
+		UpstreamDuration,
+		UpstreamInFlight,
+		RateLimitDecisions,
+		ConfigReloads,
+		ConfigLastReload,
+	)
+}
+
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
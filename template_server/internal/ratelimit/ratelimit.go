@@ -0,0 +1,189 @@
+// Package ratelimit 按配置规则对管理操作做令牌桶限流，维度为操作人（含 API token）、provider 与读写类别。
+package ratelimit
+
+import (
+	"math"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"appbox/appbox_server/internal/config"
+	"appbox/appbox_server/internal/metrics"
+	"appbox/appbox_server/internal/rbac"
+)
+
+// idleTimeout 之后仍处于满桶状态的令牌桶会被回收，避免操作人维度的桶无限增长
+const (
+	idleTimeout   = 10 * time.Minute
+	sweepInterval = time.Minute
+)
+
+// Class 返回操作的读写类别，网关自身操作返回空串（不参与限流）
+func Class(operation string) string {
+	switch operation {
+	case rbac.OpListUsers, rbac.OpListUserPlanets, rbac.OpListConfigs:
+		return config.RateLimitClassRead
+	case rbac.OpUpdateUser, rbac.OpDeleteUser, rbac.OpUpsertConfig, rbac.OpDeleteConfig:
+		return config.RateLimitClassWrite
+	default:
+		return ""
+	}
+}
+
+// Decision 为一次限流判定结果，被拒绝时 Rule 为触发限流的规则名
+type Decision struct {
+	Allowed    bool
+	Rule       string
+	RetryAfter time.Duration
+}
+
+type bucketKey struct {
+	rule     string
+	provider string
+	operator string
+}
+
+type bucket struct {
+	tokens   float64
+	updated  time.Time
+	capacity float64
+	// refill 为每秒补充的令牌数
+	refill float64
+}
+
+// Limiter 持有当前规则与全部令牌桶，配置热加载时重置
+type Limiter struct {
+	mu        sync.Mutex
+	cfg       config.RateLimitConfig
+	buckets   map[bucketKey]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+
+	tokensDesc *prometheus.Desc
+}
+
+func NewLimiter(cfg config.RateLimitConfig) *Limiter {
+	return &Limiter{
+		cfg:     cfg,
+		buckets: make(map[bucketKey]*bucket),
+		now:     time.Now,
+		tokensDesc: prometheus.NewDesc("appbox_rate_limit_tokens_available",
+			"Tokens currently available per rate limit bucket (operator is empty for provider-scoped rules).",
+			[]string{"rule", "provider", "operator"}, nil),
+	}
+}
+
+// Update 替换限流规则；规则变化时清空全部令牌桶，未变化时保留当前计数
+func (l *Limiter) Update(cfg config.RateLimitConfig) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if reflect.DeepEqual(l.cfg, cfg) {
+		return
+	}
+	l.cfg = cfg
+	l.buckets = make(map[bucketKey]*bucket)
+}
+
+// Allow 判定操作人对 provider 的一次 operation 是否放行；provider 必须是注册中心中的名称（会作为指标标签），
+// 请求需同时通过所有匹配规则，任一规则拒绝时已扣除的令牌会退回
+func (l *Limiter) Allow(operator, provider, operation string) Decision {
+	class := Class(operation)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.cfg.Enabled || class == "" {
+		return Decision{Allowed: true}
+	}
+
+	now := l.now()
+	l.sweep(now)
+	taken := make([]*bucket, 0, len(l.cfg.Rules))
+	matched := make([]string, 0, len(l.cfg.Rules))
+	for _, rule := range l.cfg.Rules {
+		if !matches(rule, operator, provider, class) {
+			continue
+		}
+		key := bucketKey{rule: rule.Name, provider: provider}
+		if rule.Scope == config.RateLimitScopeOperator {
+			key.operator = operator
+		}
+		b := l.bucket(key, rule, now)
+		if b.tokens < 1 {
+			for _, previous := range taken {
+				previous.tokens++
+			}
+			metrics.RateLimitDecisions.WithLabelValues(rule.Name, provider, class, "limited").Inc()
+			wait := time.Duration(math.Ceil((1 - b.tokens) / b.refill * float64(time.Second)))
+			return Decision{Rule: rule.Name, RetryAfter: wait}
+		}
+		b.tokens--
+		taken = append(taken, b)
+		matched = append(matched, rule.Name)
+	}
+	for _, name := range matched {
+		metrics.RateLimitDecisions.WithLabelValues(name, provider, class, "allowed").Inc()
+	}
+	return Decision{Allowed: true}
+}
+
+// bucket 返回补充到 now 的令牌桶，不存在时以满桶创建
+func (l *Limiter) bucket(key bucketKey, rule config.RateLimitRule, now time.Time) *bucket {
+	b, ok := l.buckets[key]
+	if !ok {
+		// 调用方传入的字符串可能引用 Fiber 复用的请求缓冲区，作为长期保存的 key 前需要复制
+		key.provider, key.operator = strings.Clone(key.provider), strings.Clone(key.operator)
+		b = &bucket{
+			tokens:   float64(rule.Burst),
+			updated:  now,
+			capacity: float64(rule.Burst),
+			refill:   rule.Rate / rule.Per.Seconds(),
+		}
+		l.buckets[key] = b
+		return b
+	}
+	b.tokens = math.Min(b.capacity, b.tokens+now.Sub(b.updated).Seconds()*b.refill)
+	b.updated = now
+	return b
+}
+
+// sweep 回收长时间未使用且已补满的令牌桶，调用方需持有锁
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		idle := now.Sub(b.updated)
+		if idle >= idleTimeout && b.tokens+idle.Seconds()*b.refill >= b.capacity {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+func matches(rule config.RateLimitRule, operator, provider, class string) bool {
+	if rule.Class != config.RateLimitClassAll && rule.Class != class {
+		return false
+	}
+	if !slices.Contains(rule.Providers, config.RBACWildcard) && !slices.Contains(rule.Providers, provider) {
+		return false
+	}
+	return len(rule.Operators) == 0 || slices.Contains(rule.Operators, operator)
+}
+
+func (l *Limiter) Describe(ch chan<- *prometheus.Desc) {
+	ch <- l.tokensDesc
+}
+
+// Collect 输出各令牌桶在抓取时刻的可用令牌数
+func (l *Limiter) Collect(ch chan<- prometheus.Metric) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	for key, b := range l.buckets {
+		tokens := math.Min(b.capacity, b.tokens+now.Sub(b.updated).Seconds()*b.refill)
+		ch <- prometheus.MustNewConstMetric(l.tokensDesc, prometheus.GaugeValue, tokens, key.rule, key.provider, key.operator)
+	}
+}
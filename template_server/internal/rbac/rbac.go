@@ -0,0 +1,191 @@
+// Package rbac 按配置的角色策略判定操作人能否对某个 provider 执行某个管理操作。
+package rbac
+
+import (
+	"slices"
+	"sort"
+	"sync/atomic"
+
+	"appbox/appbox_server/internal/config"
+	"appbox/appbox_server/internal/requestctx"
+)
+
+// 可授权的操作名，与 AdminProvider 方法名一致
+const (
+	OpListUsers       = "ListUsers"
+	OpListUserPlanets = "ListUserPlanets"
+	OpUpdateUser      = "UpdateUser"
+	OpDeleteUser      = "DeleteUser"
+	OpListConfigs     = "ListConfigs"
+	OpUpsertConfig    = "UpsertConfig"
+	OpDeleteConfig    = "DeleteConfig"
+	// 网关自身的操作，不属于任何 provider，仅 providers 含 * 的授权可覆盖
+	OpListAudit    = "ListAudit"
+	OpManageTokens = "ManageTokens"
+)
+
+// ProviderOperations 为作用于单个 provider 的操作
+var ProviderOperations = []string{
+	OpListUsers, OpListUserPlanets, OpUpdateUser, OpDeleteUser, OpListConfigs, OpUpsertConfig, OpDeleteConfig,
+}
+
+// GatewayOperations 为网关自身的操作
+var GatewayOperations = []string{OpListAudit, OpManageTokens}
+
+// TokenOperations 为 API token 可被授予的操作，token 不能管理 token
+var TokenOperations = append(slices.Clone(ProviderOperations), OpListAudit)
+
+// Policy 为不可变的角色策略，未启用时放行全部操作
+type Policy struct {
+	enabled   bool
+	anonymous []string
+	grants    map[string][]grant
+}
+
+type grant struct {
+	providers  map[string]bool
+	operations map[string]bool
+}
+
+func NewPolicy(cfg config.RBACConfig) *Policy {
+	policy := &Policy{
+		enabled:   cfg.Enabled,
+		anonymous: cfg.AnonymousRoles,
+		grants:    make(map[string][]grant, len(cfg.Roles)),
+	}
+	for _, role := range cfg.Roles {
+		for _, permission := range role.Permissions {
+			policy.grants[role.Name] = append(policy.grants[role.Name], grant{
+				providers:  toSet(permission.Providers),
+				operations: toSet(permission.Operations),
+			})
+		}
+	}
+	return policy
+}
+
+func (p *Policy) Enabled() bool {
+	return p.enabled
+}
+
+// EffectiveRoles 返回参与判定的角色：已识别身份使用其自身角色，否则使用 anonymous_roles
+func (p *Policy) EffectiveRoles(roles []string, identified bool) []string {
+	if identified {
+		return roles
+	}
+	return p.anonymous
+}
+
+// Allowed 判定 roles 能否对 provider 执行 operation；网关操作传入空 provider
+func (p *Policy) Allowed(roles []string, provider, operation string) bool {
+	if !p.enabled {
+		return true
+	}
+	for _, role := range roles {
+		for _, g := range p.grants[role] {
+			if g.allows(provider, operation) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// AllowedFor 判定操作人能否对 provider 执行 operation：API token 只按其 Scope 判定（不受 enabled 影响），
+// 其他操作人按角色策略判定
+func (p *Policy) AllowedFor(operator requestctx.Operator, identified bool, provider, operation string) bool {
+	if operator.Scope != nil {
+		return ScopeAllows(*operator.Scope, provider, operation)
+	}
+	return p.Allowed(p.EffectiveRoles(operator.Roles, identified), provider, operation)
+}
+
+// Permissions 列出操作人在各 provider 上允许的操作，以及允许的网关操作
+func (p *Policy) Permissions(operator requestctx.Operator, identified bool, providers []string) (map[string][]string, []string) {
+	allowed := func(provider string, operations []string) []string {
+		items := make([]string, 0, len(operations))
+		for _, operation := range operations {
+			if p.AllowedFor(operator, identified, provider, operation) {
+				items = append(items, operation)
+			}
+		}
+		sort.Strings(items)
+		return items
+	}
+
+	byProvider := make(map[string][]string, len(providers))
+	for _, provider := range providers {
+		byProvider[provider] = allowed(provider, ProviderOperations)
+	}
+	return byProvider, allowed("", GatewayOperations)
+}
+
+// MissingScope 返回 scope 会授予、但操作人自身不具备的第一项权限，防止签发超出自身权限的 API token；
+// providers 含 * 时要求操作人拥有 providers 为 * 的授权，返回的 provider 为 *
+func (p *Policy) MissingScope(operator requestctx.Operator, identified bool, scope requestctx.Scope) (string, string, bool) {
+	operations := scope.Operations
+	if slices.Contains(operations, config.RBACWildcard) {
+		operations = TokenOperations
+	}
+	providers := scope.Providers
+	if slices.Contains(providers, config.RBACWildcard) {
+		providers = []string{config.RBACWildcard}
+	}
+	for _, provider := range providers {
+		target := provider
+		if provider == config.RBACWildcard {
+			target = ""
+		}
+		for _, operation := range operations {
+			// 网关操作只在 providers 含 * 时才会被 token 授予
+			if target != "" && !slices.Contains(ProviderOperations, operation) {
+				continue
+			}
+			if !p.AllowedFor(operator, identified, target, operation) {
+				return provider, operation, true
+			}
+		}
+	}
+	return "", "", false
+}
+
+// ScopeAllows 判定 token 授权范围是否覆盖 (provider, operation)，网关操作需要 providers 含 *
+func ScopeAllows(scope requestctx.Scope, provider, operation string) bool {
+	if operation == OpManageTokens {
+		return false
+	}
+	providerOK := slices.Contains(scope.Providers, config.RBACWildcard) || (provider != "" && slices.Contains(scope.Providers, provider))
+	return providerOK && (slices.Contains(scope.Operations, config.RBACWildcard) || slices.Contains(scope.Operations, operation))
+}
+
+func (g grant) allows(provider, operation string) bool {
+	providerOK := g.providers[config.RBACWildcard] || (provider != "" && g.providers[provider])
+	return providerOK && (g.operations[config.RBACWildcard] || g.operations[operation])
+}
+
+func toSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, value := range values {
+		set[value] = true
+	}
+	return set
+}
+
+// Authorizer 持有当前生效的策略，配置热加载时整体替换
+type Authorizer struct {
+	policy atomic.Pointer[Policy]
+}
+
+func NewAuthorizer(cfg config.RBACConfig) *Authorizer {
+	authorizer := &Authorizer{}
+	authorizer.Update(cfg)
+	return authorizer
+}
+
+func (a *Authorizer) Update(cfg config.RBACConfig) {
+	a.policy.Store(NewPolicy(cfg))
+}
+
+func (a *Authorizer) Policy() *Policy {
+	return a.policy.Load()
+}
@@ -0,0 +1,58 @@
+package requestctx
+
+import "context"
+
+// AnonymousOperator 为未携带操作人标识时记录的占位值
+const AnonymousOperator = "anonymous"
+
+// Operator 为 nginx gate 认证后的管理端操作人；Source 标明身份来源（header、jwt、token），
+// Scope 仅 API token 设置，限定其可访问的 provider 与操作
+type Operator struct {
+	ID     string
+	Roles  []string
+	Source string
+	Scope  *Scope
+}
+
+// Scope 为 API token 的授权范围，* 表示全部
+type Scope struct {
+	Providers  []string
+	Operations []string
+}
+
+type operatorKey struct{}
+type clientIPKey struct{}
+
+// WithOperator 记录发起本次请求的管理端操作人
+func WithOperator(ctx context.Context, operator Operator) context.Context {
+	return context.WithValue(ctx, operatorKey{}, operator)
+}
+
+// OperatorFrom 返回当前请求的操作人，未识别身份时 ok 为 false
+func OperatorFrom(ctx context.Context) (Operator, bool) {
+	if ctx == nil {
+		return Operator{}, false
+	}
+	operator, ok := ctx.Value(operatorKey{}).(Operator)
+	return operator, ok && operator.ID != ""
+}
+
+// OperatorID 返回操作人标识，未识别身份时返回 AnonymousOperator
+func OperatorID(ctx context.Context) string {
+	if operator, ok := OperatorFrom(ctx); ok {
+		return operator.ID
+	}
+	return AnonymousOperator
+}
+
+func WithClientIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, clientIPKey{}, ip)
+}
+
+func ClientIP(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	ip, _ := ctx.Value(clientIPKey{}).(string)
+	return ip
+}
@@ -0,0 +1,56 @@
+// Package requestctx 保存单个入站请求的关联信息（请求 ID 与 W3C trace context），
+// 供 handler 写入响应、upstream 写入上游请求头与日志使用。
+package requestctx
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+)
+
+const (
+	HeaderRequestID   = "X-Request-ID"
+	HeaderTraceParent = "traceparent"
+	HeaderTraceState  = "tracestate"
+)
+
+type requestIDKey struct{}
+type traceKey struct{}
+
+// maxRequestIDLength 限制透传的外部请求 ID 长度，超长或含非法字符时重新生成
+const maxRequestIDLength = 128
+
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+func RequestID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// NormalizeRequestID 校验外部传入的请求 ID，仅接受可打印 ASCII 且不含空白的值，否则生成新的 ID
+func NormalizeRequestID(raw string) string {
+	if raw == "" || len(raw) > maxRequestIDLength {
+		return NewRequestID()
+	}
+	for i := 0; i < len(raw); i++ {
+		if raw[i] <= ' ' || raw[i] > '~' {
+			return NewRequestID()
+		}
+	}
+	return raw
+}
+
+func NewRequestID() string {
+	return randomHex(16)
+}
+
+func randomHex(n int) string {
+	buf := make([]byte, n)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,71 @@
+package requestctx
+
+import (
+	"context"
+	"encoding/hex"
+	"strings"
+)
+
+// TraceContext 为 W3C trace context：TraceID 贯穿整条链路，SpanID 为网关处理当前请求的 span
+type TraceContext struct {
+	TraceID string
+	SpanID  string
+	Flags   string
+	State   string
+}
+
+func WithTrace(ctx context.Context, trace TraceContext) context.Context {
+	return context.WithValue(ctx, traceKey{}, trace)
+}
+
+func Trace(ctx context.Context) (TraceContext, bool) {
+	if ctx == nil {
+		return TraceContext{}, false
+	}
+	trace, ok := ctx.Value(traceKey{}).(TraceContext)
+	return trace, ok
+}
+
+// ContinueTrace 解析入站 traceparent 并为网关生成新的 span；缺失或格式错误时开启新的 trace
+func ContinueTrace(traceparent, tracestate string) TraceContext {
+	if traceID, flags, ok := parseTraceParent(traceparent); ok {
+		return TraceContext{TraceID: traceID, SpanID: randomHex(8), Flags: flags, State: tracestate}
+	}
+	return TraceContext{TraceID: randomHex(16), SpanID: randomHex(8), Flags: "01"}
+}
+
+// Child 返回同一 trace 下的新 span，每次上游尝试使用独立的 span ID
+func (t TraceContext) Child() TraceContext {
+	t.SpanID = randomHex(8)
+	return t
+}
+
+func (t TraceContext) TraceParent() string {
+	return "00-" + t.TraceID + "-" + t.SpanID + "-" + t.Flags
+}
+
+func parseTraceParent(value string) (traceID, flags string, ok bool) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" {
+		return "", "", false
+	}
+	if parts[0] == "00" && len(parts) != 4 {
+		return "", "", false
+	}
+	traceID, spanID, flags := strings.ToLower(parts[1]), strings.ToLower(parts[2]), strings.ToLower(parts[3])
+	if !isHex(parts[0], 2) || !isHex(traceID, 32) || !isHex(spanID, 16) || !isHex(flags, 2) {
+		return "", "", false
+	}
+	if strings.Trim(traceID, "0") == "" || strings.Trim(spanID, "0") == "" {
+		return "", "", false
+	}
+	return traceID, flags, true
+}
+
+func isHex(value string, length int) bool {
+	if len(value) != length {
+		return false
+	}
+	_, err := hex.DecodeString(value)
+	return err == nil
+}
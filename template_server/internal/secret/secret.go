@@ -0,0 +1,104 @@
+package secret
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	filePrefix = "file:"
+	envPrefix  = "env:"
+
+	fileCheckInterval = time.Second
+)
+
+// Source 表示一个可延迟解析的密钥，每次使用时调用 Value 获取当前值
+type Source interface {
+	Value() (string, error)
+}
+
+// New 解析密钥引用：`file:/path` 从文件读取并在文件变化后自动重新加载，
+// `env:NAME` 从环境变量读取，其余按字面量处理
+func New(ref string) Source {
+	ref = strings.TrimSpace(ref)
+	switch {
+	case strings.HasPrefix(ref, filePrefix):
+		return &fileSource{path: strings.TrimSpace(strings.TrimPrefix(ref, filePrefix))}
+	case strings.HasPrefix(ref, envPrefix):
+		return envSource(strings.TrimSpace(strings.TrimPrefix(ref, envPrefix)))
+	default:
+		return literalSource(ref)
+	}
+}
+
+// Describe 返回不含密钥明文的引用描述，用于日志
+func Describe(ref string) string {
+	ref = strings.TrimSpace(ref)
+	if strings.HasPrefix(ref, filePrefix) || strings.HasPrefix(ref, envPrefix) {
+		return ref
+	}
+	if ref == "" {
+		return "(empty)"
+	}
+	return "(literal)"
+}
+
+type literalSource string
+
+func (s literalSource) Value() (string, error) {
+	return string(s), nil
+}
+
+type envSource string
+
+func (s envSource) Value() (string, error) {
+	value, ok := os.LookupEnv(string(s))
+	if !ok || strings.TrimSpace(value) == "" {
+		return "", fmt.Errorf("environment variable %s is not set", string(s))
+	}
+	return strings.TrimSpace(value), nil
+}
+
+type fileSource struct {
+	path      string
+	mu        sync.Mutex
+	value     string
+	modTime   time.Time
+	size      int64
+	checkedAt time.Time
+}
+
+func (s *fileSource) Value() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if s.value != "" && now.Sub(s.checkedAt) < fileCheckInterval {
+		return s.value, nil
+	}
+	s.checkedAt = now
+
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return "", fmt.Errorf("read secret file %s failed: %w", s.path, err)
+	}
+	if s.value != "" && info.ModTime().Equal(s.modTime) && info.Size() == s.size {
+		return s.value, nil
+	}
+
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return "", fmt.Errorf("read secret file %s failed: %w", s.path, err)
+	}
+	value := strings.TrimSpace(string(data))
+	if value == "" {
+		return "", fmt.Errorf("secret file %s is empty", s.path)
+	}
+	s.value = value
+	s.modTime = info.ModTime()
+	s.size = info.Size()
+	return s.value, nil
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"appbox/appbox_server/internal/audit"
+	"appbox/appbox_server/internal/dto"
+	"appbox/appbox_server/internal/requestctx"
+	"appbox/appbox_server/internal/upstream"
+	"appbox/appbox_server/pkg/logger"
+)
+
+// auditedProvider 为变更类操作（UpdateUser、DeleteUser、UpsertConfig、DeleteConfig）写入审计记录；
+// 审计写入失败只记录错误日志，不影响操作结果
+type auditedProvider struct {
+	AdminProvider
+	store audit.Store
+}
+
+// Audit 包装 provider 以记录变更类操作，store 为 nil（审计关闭）时原样返回
+func Audit(provider AdminProvider, store audit.Store) AdminProvider {
+	if provider == nil || store == nil {
+		return provider
+	}
+	return &auditedProvider{AdminProvider: provider, store: store}
+}
+
+func (p *auditedProvider) record(ctx context.Context, operation, targetID string, payload interface{}, start time.Time, err error) {
+	entry := dto.AuditEntry{
+		Time:       start.UnixMilli(),
+		Operator:   requestctx.OperatorID(ctx),
+		ClientIP:   requestctx.ClientIP(ctx),
+		RequestID:  requestctx.RequestID(ctx),
+		Provider:   p.Name(),
+		Operation:  operation,
+		TargetID:   targetID,
+		Result:     audit.ResultSuccess,
+		StatusCode: http.StatusOK,
+		LatencyMs:  time.Since(start).Milliseconds(),
+	}
+	if payload != nil {
+		if encoded, marshalErr := json.Marshal(payload); marshalErr == nil {
+			entry.Payload = encoded
+		}
+	}
+	if err != nil {
+		entry.Result = audit.ResultFailure
+		entry.Error = err.Error()
+		entry.StatusCode = http.StatusInternalServerError
+		var upErr *upstream.Error
+		switch {
+		case errors.As(err, &upErr):
+			entry.StatusCode = upErr.StatusCode
+			entry.Error = upErr.Message
+		case errors.Is(err, ErrOperationNotSupported):
+			entry.StatusCode = http.StatusNotImplemented
+		}
+	}
+
+	// 请求被取消时仍需落盘，因此不沿用请求 context
+	if appendErr := p.store.Append(context.WithoutCancel(ctx), entry); appendErr != nil {
+		logger.FromContext(ctx).Error("write audit entry failed", "error", appendErr)
+	}
+}
+
+func (p *auditedProvider) UpdateUser(ctx context.Context, userID uint, req dto.AdminUserUpdateRequest) (*dto.User, error) {
+	start := time.Now()
+	result, err := p.AdminProvider.UpdateUser(ctx, userID, req)
+	p.record(ctx, "UpdateUser", strconv.FormatUint(uint64(userID), 10), req, start, err)
+	return result, err
+}
+
+func (p *auditedProvider) DeleteUser(ctx context.Context, userID uint) error {
+	start := time.Now()
+	err := p.AdminProvider.DeleteUser(ctx, userID)
+	p.record(ctx, "DeleteUser", strconv.FormatUint(uint64(userID), 10), nil, start, err)
+	return err
+}
+
+func (p *auditedProvider) UpsertConfig(ctx context.Context, key string, req dto.AppConfigUpsertRequest) (*dto.AppConfig, error) {
+	start := time.Now()
+	result, err := p.AdminProvider.UpsertConfig(ctx, key, req)
+	p.record(ctx, "UpsertConfig", key, req, start, err)
+	return result, err
+}
+
+func (p *auditedProvider) DeleteConfig(ctx context.Context, key string) error {
+	start := time.Now()
+	err := p.AdminProvider.DeleteConfig(ctx, key)
+	p.record(ctx, "DeleteConfig", key, nil, start, err)
+	return err
+}
@@ -0,0 +1,13 @@
+package service
+
+import "errors"
+
+type Capability string
+
+const (
+	CapabilityUsers   Capability = "users"
+	CapabilityPlanets Capability = "planets"
+	CapabilityConfigs Capability = "configs"
+)
+
+var ErrOperationNotSupported = errors.New("operation not supported by provider")
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"appbox/appbox_server/internal/dto"
+	"appbox/appbox_server/pkg/logger"
+)
+
+const (
+	HealthStatusUnknown = "unknown"
+	HealthStatusUp      = "up"
+	HealthStatusDown    = "down"
+	// HealthStatusDisabled 表示 provider 未开启主动探测，不参与就绪判断与整体健康状态
+	HealthStatusDisabled = "disabled"
+)
+
+// HealthChecker 由支持主动探测的 provider 实现，HealthCheckInterval 返回 0 表示不探测
+type HealthChecker interface {
+	CheckHealth(ctx context.Context) (HealthResult, error)
+	HealthCheckInterval() time.Duration
+}
+
+type HealthResult struct {
+	Latency time.Duration
+	Version string
+}
+
+type healthState struct {
+	provider  AdminProvider
+	health    dto.ProviderHealth
+	nextProbe time.Time
+	probing   bool
+}
+
+type HealthMonitor struct {
+	registry *ProviderRegistry
+	mu       sync.RWMutex
+	states   map[string]*healthState
+}
+
+func NewHealthMonitor(registry *ProviderRegistry) *HealthMonitor {
+	return &HealthMonitor{
+		registry: registry,
+		states:   make(map[string]*healthState),
+	}
+}
+
+// Run 每秒检查一次注册中心内到期的 provider 并异步探测，直到 ctx 结束
+func (m *HealthMonitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	m.probeDue(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.probeDue(ctx)
+		}
+	}
+}
+
+func (m *HealthMonitor) Status(key string) dto.ProviderHealth {
+	m.mu.RLock()
+	state, ok := m.states[key]
+	var health dto.ProviderHealth
+	if ok {
+		health = state.health
+	}
+	m.mu.RUnlock()
+	if ok {
+		return health
+	}
+	if provider, err := m.registry.Resolve(key); err == nil && !probed(provider) {
+		return dto.ProviderHealth{Status: HealthStatusDisabled}
+	}
+	return dto.ProviderHealth{Status: HealthStatusUnknown}
+}
+
+func probed(provider AdminProvider) bool {
+	checker, ok := provider.(HealthChecker)
+	return ok && checker.HealthCheckInterval() > 0
+}
+
+func (m *HealthMonitor) Snapshot() map[string]dto.ProviderHealth {
+	providers := m.registry.Snapshot()
+	result := make(map[string]dto.ProviderHealth, len(providers))
+	for key := range providers {
+		result[key] = m.Status(key)
+	}
+	return result
+}
+
+// Ready 以默认 provider 的探测结果作为网关就绪条件，默认 provider 未开启探测时视为就绪
+func (m *HealthMonitor) Ready() (bool, string) {
+	key := m.registry.DefaultKey()
+	if _, err := m.registry.Resolve(key); err != nil {
+		return false, err.Error()
+	}
+	health := m.Status(key)
+	if health.Status != HealthStatusUp && health.Status != HealthStatusDisabled {
+		return false, fmt.Sprintf("default provider %s is %s", key, health.Status)
+	}
+	return true, ""
+}
+
+func (m *HealthMonitor) probeDue(ctx context.Context) {
+	providers := m.registry.Snapshot()
+	now := time.Now()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for key := range m.states {
+		// provider 被移除或热加载后关闭了探测时丢弃旧状态，避免一直报告过期的结果
+		if provider, ok := providers[key]; !ok || !probed(provider) {
+			delete(m.states, key)
+		}
+	}
+	for key, provider := range providers {
+		if !probed(provider) {
+			continue
+		}
+		checker := provider.(HealthChecker)
+		state, ok := m.states[key]
+		if !ok {
+			state = &healthState{health: dto.ProviderHealth{Status: HealthStatusUnknown}}
+			m.states[key] = state
+		}
+		if state.provider != provider {
+			// 配置重载后 provider 实例被替换，立即重新探测
+			state.provider = provider
+			state.nextProbe = now
+		}
+		if state.probing || now.Before(state.nextProbe) {
+			continue
+		}
+		state.probing = true
+		go m.probe(ctx, key, checker)
+	}
+}
+
+func (m *HealthMonitor) probe(ctx context.Context, key string, checker HealthChecker) {
+	result, err := checker.CheckHealth(ctx)
+	health := dto.ProviderHealth{
+		Status:    HealthStatusUp,
+		LatencyMs: result.Latency.Milliseconds(),
+		Version:   result.Version,
+		CheckedAt: time.Now().UnixMilli(),
+	}
+	if err != nil {
+		health.Status = HealthStatusDown
+		health.LastError = err.Error()
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	state, ok := m.states[key]
+	if !ok {
+		return
+	}
+	if state.health.Status != health.Status {
+		if err != nil {
+			logger.Warnf("provider %s health: %s -> %s: %v", key, state.health.Status, health.Status, err)
+		} else {
+			logger.Infof("provider %s health: %s -> %s", key, state.health.Status, health.Status)
+		}
+	}
+	if err == nil {
+		health.LastError = state.health.LastError
+	} else {
+		health.Version = state.health.Version
+	}
+	state.health = health
+	state.probing = false
+	state.nextProbe = time.Now().Add(checker.HealthCheckInterval())
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"appbox/appbox_server/internal/config"
+	"appbox/appbox_server/internal/dto"
+	"appbox/appbox_server/internal/secret"
+	"appbox/appbox_server/internal/upstream"
+)
+
+type httpProvider struct {
+	cfg    config.AppProviderConfig
+	client *upstream.Client
+}
+
+func NewHTTPProvider(cfg config.AppProviderConfig) (AdminProvider, error) {
+	tlsCfg, err := cfg.TLS.Build()
+	if err != nil {
+		return nil, fmt.Errorf("provider %s tls config invalid: %w", cfg.Name, err)
+	}
+	return &httpProvider{
+		cfg: cfg,
+		client: upstream.NewClient(upstream.Config{
+			Name:    cfg.Name,
+			BaseURL: cfg.BaseURL,
+			Timeout: cfg.Timeout,
+			TLS:     tlsCfg,
+			Transport: upstream.TransportConfig{
+				MaxIdleConns:          cfg.Transport.MaxIdleConns,
+				MaxIdleConnsPerHost:   cfg.Transport.MaxIdleConnsPerHost,
+				MaxConnsPerHost:       cfg.Transport.MaxConnsPerHost,
+				DialTimeout:           cfg.Transport.DialTimeout,
+				KeepAlive:             cfg.Transport.KeepAlive,
+				TLSHandshakeTimeout:   cfg.Transport.TLSHandshakeTimeout,
+				ResponseHeaderTimeout: cfg.Transport.ResponseHeaderTimeout,
+				IdleConnTimeout:       cfg.Transport.IdleConnTimeout,
+				DisableKeepAlives:     cfg.Transport.DisableKeepAlives,
+				HTTP2:                 cfg.Transport.HTTP2,
+			},
+			Auth: gatewayAuth(cfg),
+			Retry: upstream.RetryPolicy{
+				MaxAttempts:       cfg.Retry.MaxAttempts,
+				InitialBackoff:    cfg.Retry.InitialBackoff,
+				MaxBackoff:        cfg.Retry.MaxBackoff,
+				Jitter:            cfg.Retry.Jitter,
+				RetryOnStatus:     cfg.Retry.RetryOnStatus,
+				IdempotentMethods: cfg.Retry.IdempotentMethods,
+			},
+			Breaker: upstream.BreakerConfig{
+				Enabled:          cfg.Breaker.Enabled,
+				FailureRatio:     cfg.Breaker.FailureRatio,
+				MinRequests:      cfg.Breaker.MinRequests,
+				Window:           cfg.Breaker.Window,
+				CoolDown:         cfg.Breaker.CoolDown,
+				HalfOpenRequests: cfg.Breaker.HalfOpenRequests,
+			},
+			Bulkhead: upstream.BulkheadConfig{
+				MaxInFlight: cfg.Bulkhead.MaxInFlight,
+				MaxWait:     cfg.Bulkhead.MaxWait,
+			},
+		}),
+	}, nil
+}
+
+func gatewayAuth(cfg config.AppProviderConfig) upstream.Auth {
+	auth := upstream.Auth{
+		Mode:   cfg.AuthMode,
+		Header: cfg.GatewayHead,
+		Keys:   []upstream.KeySource{secret.New(cfg.GatewayKey)},
+	}
+	if cfg.GatewayKeySecondary != "" {
+		auth.Keys = append(auth.Keys, secret.New(cfg.GatewayKeySecondary))
+	}
+	return auth
+}
+
+func (p *httpProvider) Name() string {
+	return p.cfg.Name
+}
+
+func (p *httpProvider) Supports(capability Capability) bool {
+	for _, item := range p.cfg.Capabilities {
+		if Capability(item) == capability {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *httpProvider) CheckHealth(ctx context.Context) (HealthResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.cfg.Health.Timeout)
+	defer cancel()
+	result, err := p.client.Probe(ctx, p.cfg.Health.Path)
+	return HealthResult{Latency: result.Latency, Version: result.Version}, err
+}
+
+func (p *httpProvider) HealthCheckInterval() time.Duration {
+	if !p.cfg.Health.Enabled {
+		return 0
+	}
+	return p.cfg.Health.Interval
+}
+
+func (p *httpProvider) Describe() dto.ProviderItem {
+	displayName := p.cfg.DisplayName
+	if displayName == "" {
+		displayName = p.cfg.Name
+	}
+	host := ""
+	if parsed, err := url.Parse(p.cfg.BaseURL); err == nil {
+		host = parsed.Host
+	}
+	return dto.ProviderItem{
+		Key:          p.cfg.Name,
+		DisplayName:  displayName,
+		Description:  p.cfg.Description,
+		BaseURLHost:  host,
+		Capabilities: append([]string(nil), p.cfg.Capabilities...),
+		CircuitState: string(p.client.BreakerState()),
+	}
+}
+
+func (p *httpProvider) ListUsers(ctx context.Context, page, pageSize int, keyword string) (*dto.AdminUsersPaginationResponse, error) {
+	q := url.Values{}
+	q.Set("page", fmt.Sprintf("%d", page))
+	q.Set("pageSize", fmt.Sprintf("%d", pageSize))
+	if strings.TrimSpace(keyword) != "" {
+		q.Set("keyword", strings.TrimSpace(keyword))
+	}
+
+	var result dto.AdminUsersPaginationResponse
+	if err := p.client.DoJSON(ctx, http.MethodGet, p.cfg.PathPrefix+"/users?"+q.Encode(), nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (p *httpProvider) ListUserPlanets(ctx context.Context, userID uint, page, pageSize int) (*dto.PaginationResponse[dto.PlanetItem], error) {
+	q := url.Values{}
+	q.Set("page", fmt.Sprintf("%d", page))
+	q.Set("pageSize", fmt.Sprintf("%d", pageSize))
+
+	path := fmt.Sprintf("%s/users/%d/planets?%s", p.cfg.PathPrefix, userID, q.Encode())
+	var result dto.PaginationResponse[dto.PlanetItem]
+	if err := p.client.DoJSON(ctx, http.MethodGet, path, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (p *httpProvider) UpdateUser(ctx context.Context, userID uint, req dto.AdminUserUpdateRequest) (*dto.User, error) {
+	path := fmt.Sprintf("%s/users/%d", p.cfg.PathPrefix, userID)
+	var result dto.User
+	if err := p.client.DoJSON(ctx, http.MethodPut, path, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (p *httpProvider) DeleteUser(ctx context.Context, userID uint) error {
+	path := fmt.Sprintf("%s/users/%d", p.cfg.PathPrefix, userID)
+	return p.client.DoJSON(ctx, http.MethodDelete, path, nil, nil)
+}
+
+func (p *httpProvider) ListConfigs(ctx context.Context) ([]dto.AppConfig, error) {
+	result := make([]dto.AppConfig, 0)
+	if err := p.client.DoJSON(ctx, http.MethodGet, p.cfg.PathPrefix+"/configs", nil, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (p *httpProvider) UpsertConfig(ctx context.Context, key string, req dto.AppConfigUpsertRequest) (*dto.AppConfig, error) {
+	path := fmt.Sprintf("%s/configs/%s", p.cfg.PathPrefix, url.PathEscape(key))
+	var result dto.AppConfig
+	if err := p.client.DoJSON(ctx, http.MethodPut, path, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (p *httpProvider) DeleteConfig(ctx context.Context, key string) error {
+	path := fmt.Sprintf("%s/configs/%s", p.cfg.PathPrefix, url.PathEscape(key))
+	return p.client.DoJSON(ctx, http.MethodDelete, path, nil, nil)
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"appbox/appbox_server/internal/dto"
+	"appbox/appbox_server/internal/metrics"
+	"appbox/appbox_server/internal/tracing"
+	"appbox/appbox_server/internal/upstream"
+	"appbox/appbox_server/pkg/logger"
+)
+
+// instrumentedProvider 为每次 AdminProvider 调用记录指标与日志字段，并在启用链路追踪时创建子 span（provider、操作名与目标用户/配置）
+type instrumentedProvider struct {
+	AdminProvider
+}
+
+// Instrument 包装 provider 以记录调用指标与链路追踪
+func Instrument(provider AdminProvider) AdminProvider {
+	if provider == nil {
+		return nil
+	}
+	return &instrumentedProvider{AdminProvider: provider}
+}
+
+type providerCall struct {
+	provider  string
+	operation string
+	start     time.Time
+	span      trace.Span
+}
+
+// start 创建子 span，并把同样的字段（去掉 appbox. 前缀，如 provider、operation、user_id）追加到 context 中的 logger
+func (p *instrumentedProvider) start(ctx context.Context, operation string, attrs ...attribute.KeyValue) (context.Context, *providerCall) {
+	attrs = append(attrs, tracing.AttrProvider.String(p.Name()), tracing.AttrOperation.String(operation))
+	fields := make([]any, 0, len(attrs)*2)
+	for _, attr := range attrs {
+		fields = append(fields, strings.TrimPrefix(string(attr.Key), "appbox."), attr.Value.AsInterface())
+	}
+	ctx = logger.With(ctx, fields...)
+	ctx, span := tracing.Tracer().Start(ctx, "provider."+operation, trace.WithAttributes(attrs...))
+	return ctx, &providerCall{provider: p.Name(), operation: operation, start: time.Now(), span: span}
+}
+
+func (call *providerCall) finish(err error) {
+	metrics.ProviderDuration.WithLabelValues(call.provider, call.operation).Observe(time.Since(call.start).Seconds())
+	metrics.ProviderCalls.WithLabelValues(call.provider, call.operation, upstream.Classify(err)).Inc()
+	if err != nil {
+		call.span.RecordError(err)
+		call.span.SetStatus(codes.Error, err.Error())
+	}
+	call.span.End()
+}
+
+func (p *instrumentedProvider) ListUsers(ctx context.Context, page, pageSize int, keyword string) (*dto.AdminUsersPaginationResponse, error) {
+	ctx, call := p.start(ctx, "ListUsers")
+	result, err := p.AdminProvider.ListUsers(ctx, page, pageSize, keyword)
+	call.finish(err)
+	return result, err
+}
+
+func (p *instrumentedProvider) ListUserPlanets(ctx context.Context, userID uint, page, pageSize int) (*dto.PaginationResponse[dto.PlanetItem], error) {
+	ctx, call := p.start(ctx, "ListUserPlanets", tracing.AttrUserID.Int64(int64(userID)))
+	result, err := p.AdminProvider.ListUserPlanets(ctx, userID, page, pageSize)
+	call.finish(err)
+	return result, err
+}
+
+func (p *instrumentedProvider) UpdateUser(ctx context.Context, userID uint, req dto.AdminUserUpdateRequest) (*dto.User, error) {
+	ctx, call := p.start(ctx, "UpdateUser", tracing.AttrUserID.Int64(int64(userID)))
+	result, err := p.AdminProvider.UpdateUser(ctx, userID, req)
+	call.finish(err)
+	return result, err
+}
+
+func (p *instrumentedProvider) DeleteUser(ctx context.Context, userID uint) error {
+	ctx, call := p.start(ctx, "DeleteUser", tracing.AttrUserID.Int64(int64(userID)))
+	err := p.AdminProvider.DeleteUser(ctx, userID)
+	call.finish(err)
+	return err
+}
+
+func (p *instrumentedProvider) ListConfigs(ctx context.Context) ([]dto.AppConfig, error) {
+	ctx, call := p.start(ctx, "ListConfigs")
+	result, err := p.AdminProvider.ListConfigs(ctx)
+	call.finish(err)
+	return result, err
+}
+
+func (p *instrumentedProvider) UpsertConfig(ctx context.Context, key string, req dto.AppConfigUpsertRequest) (*dto.AppConfig, error) {
+	// key 通常取自路由参数（引用 Fiber 复用的请求缓冲区），span 在请求结束后才导出，需要复制
+	ctx, call := p.start(ctx, "UpsertConfig", tracing.AttrConfigKey.String(strings.Clone(key)))
+	result, err := p.AdminProvider.UpsertConfig(ctx, key, req)
+	call.finish(err)
+	return result, err
+}
+
+func (p *instrumentedProvider) DeleteConfig(ctx context.Context, key string) error {
+	ctx, call := p.start(ctx, "DeleteConfig", tracing.AttrConfigKey.String(strings.Clone(key)))
+	err := p.AdminProvider.DeleteConfig(ctx, key)
+	call.finish(err)
+	return err
+}
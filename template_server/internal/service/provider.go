@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 	"sync"
 
@@ -11,6 +12,7 @@ import (
 
 type AdminProvider interface {
 	Name() string
+	Supports(capability Capability) bool
 	ListUsers(ctx context.Context, page, pageSize int, keyword string) (*dto.AdminUsersPaginationResponse, error)
 	ListUserPlanets(ctx context.Context, userID uint, page, pageSize int) (*dto.PaginationResponse[dto.PlanetItem], error)
 	UpdateUser(ctx context.Context, userID uint, req dto.AdminUserUpdateRequest) (*dto.User, error)
@@ -20,6 +22,11 @@ type AdminProvider interface {
 	DeleteConfig(ctx context.Context, key string) error
 }
 
+// Describer 由能够提供展示元数据（名称、描述、能力、熔断状态等）的 provider 实现
+type Describer interface {
+	Describe() dto.ProviderItem
+}
+
 type ProviderRegistry struct {
 	mu         sync.RWMutex
 	providers  map[string]AdminProvider
@@ -33,20 +40,70 @@ func NewProviderRegistry(defaultKey string) *ProviderRegistry {
 	}
 }
 
-func (r *ProviderRegistry) Register(key string, provider AdminProvider) {
+// Replace 原子替换全部 provider 与默认 key，启动与热加载均通过它修改注册中心；已解析出旧 provider 的进行中请求不受影响
+func (r *ProviderRegistry) Replace(providers map[string]AdminProvider, defaultKey string) error {
+	defaultKey = strings.TrimSpace(defaultKey)
+	if _, ok := providers[defaultKey]; len(providers) > 0 && !ok {
+		return fmt.Errorf("default provider %q is not registered", defaultKey)
+	}
+
+	next := make(map[string]AdminProvider, len(providers))
+	for key, provider := range providers {
+		next[strings.TrimSpace(key)] = provider
+	}
+
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	r.providers[strings.TrimSpace(key)] = provider
+	r.providers = next
+	r.defaultKey = defaultKey
+	return nil
+}
+
+func (r *ProviderRegistry) List() []dto.ProviderItem {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	items := make([]dto.ProviderItem, 0, len(r.providers))
+	for key, provider := range r.providers {
+		item := dto.ProviderItem{Key: key, DisplayName: key}
+		if describer, ok := provider.(Describer); ok {
+			item = describer.Describe()
+			item.Key = key
+		}
+		item.IsDefault = key == r.defaultKey
+		items = append(items, item)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Key < items[j].Key
+	})
+	return items
+}
+
+func (r *ProviderRegistry) DefaultKey() string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.defaultKey
 }
 
-func (r *ProviderRegistry) List() []string {
+func (r *ProviderRegistry) Snapshot() map[string]AdminProvider {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
-	keys := make([]string, 0, len(r.providers))
-	for k := range r.providers {
-		keys = append(keys, k)
+	snapshot := make(map[string]AdminProvider, len(r.providers))
+	for key, provider := range r.providers {
+		snapshot[key] = provider
 	}
-	return keys
+	return snapshot
+}
+
+// ResolveFor 解析 provider 并校验其声明了 capability，未声明时返回 ErrOperationNotSupported，不会访问上游
+func (r *ProviderRegistry) ResolveFor(providerKey string, capability Capability) (AdminProvider, error) {
+	provider, err := r.Resolve(providerKey)
+	if err != nil {
+		return nil, err
+	}
+	if !provider.Supports(capability) {
+		return nil, fmt.Errorf("%w: %s does not support %s", ErrOperationNotSupported, provider.Name(), capability)
+	}
+	return provider, nil
 }
 
 func (r *ProviderRegistry) Resolve(providerKey string) (AdminProvider, error) {
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"appbox/appbox_server/internal/upstream"
+)
+
+var breakerStates = []upstream.BreakerState{upstream.BreakerClosed, upstream.BreakerOpen, upstream.BreakerHalfOpen, upstream.BreakerDisabled}
+
+// ProviderCollector 在抓取时读取注册中心与健康探测结果，输出熔断状态与 provider 可用性
+type ProviderCollector struct {
+	registry *ProviderRegistry
+	monitor  *HealthMonitor
+
+	breakerDesc *prometheus.Desc
+	upDesc      *prometheus.Desc
+}
+
+func NewProviderCollector(registry *ProviderRegistry, monitor *HealthMonitor) *ProviderCollector {
+	return &ProviderCollector{
+		registry: registry,
+		monitor:  monitor,
+		breakerDesc: prometheus.NewDesc("appbox_circuit_breaker_state",
+			"Circuit breaker state per provider (1 for the current state).", []string{"provider", "state"}, nil),
+		upDesc: prometheus.NewDesc("appbox_provider_up",
+			"Whether the last health probe of the provider succeeded.", []string{"provider"}, nil),
+	}
+}
+
+func (c *ProviderCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.breakerDesc
+	ch <- c.upDesc
+}
+
+func (c *ProviderCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, item := range c.registry.List() {
+		for _, state := range breakerStates {
+			value := 0.0
+			if item.CircuitState == string(state) {
+				value = 1
+			}
+			ch <- prometheus.MustNewConstMetric(c.breakerDesc, prometheus.GaugeValue, value, item.Key, string(state))
+		}
+
+		up := 0.0
+		if c.monitor.Status(item.Key).Status == HealthStatusUp {
+			up = 1
+		}
+		ch <- prometheus.MustNewConstMetric(c.upDesc, prometheus.GaugeValue, up, item.Key)
+	}
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+
+	"appbox/appbox_server/internal/config"
+	"appbox/appbox_server/internal/metrics"
+	"appbox/appbox_server/pkg/logger"
+)
+
+type reloadEntry struct {
+	cfg      config.AppProviderConfig
+	provider AdminProvider
+}
+
+// ProviderReloader 根据配置构建 provider 并整体替换到注册中心；
+// 配置未变化的 provider 复用原实例，以保留熔断等运行期状态
+type ProviderReloader struct {
+	mu       sync.Mutex
+	registry *ProviderRegistry
+	current  *config.Config
+	entries  map[string]reloadEntry
+	hooks    []func(*config.Config)
+}
+
+func NewProviderReloader(registry *ProviderRegistry) *ProviderReloader {
+	return &ProviderReloader{
+		registry: registry,
+		entries:  make(map[string]reloadEntry),
+	}
+}
+
+func (r *ProviderReloader) Apply(cfg *config.Config) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entries := make(map[string]reloadEntry, len(cfg.Provider.Apps))
+	providers := make(map[string]AdminProvider, len(cfg.Provider.Apps))
+	for _, appCfg := range cfg.Provider.Apps {
+		if !appCfg.Enabled {
+			continue
+		}
+		entry, ok := r.entries[appCfg.Name]
+		if !ok || !reflect.DeepEqual(entry.cfg, appCfg) {
+			provider, err := NewHTTPProvider(appCfg)
+			if err != nil {
+				return err
+			}
+			entry = reloadEntry{cfg: appCfg, provider: provider}
+			logger.Infof("provider registered: %s -> %s%s", appCfg.Name, appCfg.BaseURL, appCfg.PathPrefix)
+		}
+		entries[appCfg.Name] = entry
+		providers[appCfg.Name] = entry.provider
+	}
+
+	if err := r.registry.Replace(providers, cfg.Provider.Default); err != nil {
+		return err
+	}
+	for name := range r.entries {
+		if _, ok := entries[name]; !ok {
+			logger.Infof("provider unregistered: %s", name)
+		}
+	}
+	r.entries = entries
+	r.current = cfg
+	metrics.ConfigLastReload.SetToCurrentTime()
+	return nil
+}
+
+// OnReload 注册热加载成功后的回调，用于刷新 provider 以外支持热加载的配置（如权限策略）
+func (r *ProviderReloader) OnReload(hook func(*config.Config)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.hooks = append(r.hooks, hook)
+}
+
+// Reload 重新读取配置文件，校验通过后替换 provider；失败时保留当前配置继续服务
+func (r *ProviderReloader) Reload(reason string) error {
+	r.mu.Lock()
+	previous := r.current
+	r.mu.Unlock()
+	if previous == nil {
+		return fmt.Errorf("reloader has no active config")
+	}
+
+	next, err := config.LoadFile(previous.Path, previous.Overrides)
+	if err != nil {
+		metrics.ConfigReloads.WithLabelValues("rejected").Inc()
+		logger.Errorf("config reload (%s) rejected: %v", reason, err)
+		return err
+	}
+
+	changes := config.Diff(previous, next)
+	if len(changes) == 0 {
+		metrics.ConfigReloads.WithLabelValues("unchanged").Inc()
+		logger.Infof("config reload (%s): no changes", reason)
+		return nil
+	}
+	if err := r.Apply(next); err != nil {
+		metrics.ConfigReloads.WithLabelValues("rejected").Inc()
+		logger.Errorf("config reload (%s) rejected: %v", reason, err)
+		return err
+	}
+	metrics.ConfigReloads.WithLabelValues("applied").Inc()
+	for _, change := range changes {
+		logger.Infof("config reload (%s): %s", reason, change)
+	}
+	r.mu.Lock()
+	hooks := r.hooks
+	r.mu.Unlock()
+	for _, hook := range hooks {
+		hook(next)
+	}
+	if previous.Log.Level != next.Log.Level {
+		if err := logger.SetLevel(next.Log.Level); err == nil {
+			logger.Infof("config reload (%s): log level set to %s", reason, next.Log.Level)
+		}
+	}
+	if !reflect.DeepEqual(previous.Server, next.Server) || !reflect.DeepEqual(previous.CORS, next.CORS) ||
+		!reflect.DeepEqual(previous.Tracing, next.Tracing) || previous.Log.Format != next.Log.Format ||
+		!reflect.DeepEqual(previous.AccessLog, next.AccessLog) || !reflect.DeepEqual(previous.Audit, next.Audit) ||
+		!reflect.DeepEqual(previous.Identity, next.Identity) || !reflect.DeepEqual(previous.APITokens, next.APITokens) ||
+		!reflect.DeepEqual(previous.Search, next.Search) {
+		logger.Warnf("config reload (%s): server/cors/tracing/log.format/access_log/audit/identity/api_tokens/search changes take effect after restart", reason)
+	}
+	return nil
+}
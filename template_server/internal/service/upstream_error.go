@@ -1,18 +1,5 @@
 package service
 
-import "fmt"
+import "appbox/appbox_server/internal/upstream"
 
-type UpstreamError struct {
-	StatusCode int
-	Message    string
-}
-
-func (e *UpstreamError) Error() string {
-	if e == nil {
-		return "upstream error"
-	}
-	if e.Message == "" {
-		return fmt.Sprintf("upstream status=%d", e.StatusCode)
-	}
-	return e.Message
-}
+type UpstreamError = upstream.Error
@@ -0,0 +1,92 @@
+// Package tracing 初始化 OpenTelemetry TracerProvider，并提供各层共用的 tracer 名称与属性键。
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.43.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"appbox/appbox_server/internal/config"
+)
+
+const TracerName = "appbox/appbox_server"
+
+// 业务自定义属性键
+const (
+	AttrProvider  = attribute.Key("appbox.provider")
+	AttrOperation = attribute.Key("appbox.operation")
+	AttrUserID    = attribute.Key("appbox.user_id")
+	AttrConfigKey = attribute.Key("appbox.config_key")
+	AttrRequestID = attribute.Key("appbox.request_id")
+	AttrOperator  = attribute.Key("appbox.operator")
+)
+
+var enabled atomic.Bool
+
+func Tracer() trace.Tracer {
+	return otel.Tracer(TracerName)
+}
+
+// Enabled 表示是否已注册 SDK TracerProvider；未启用时各层跳过 span 创建，
+// traceparent 由 requestctx 直接透传
+func Enabled() bool {
+	return enabled.Load()
+}
+
+// Setup 按配置注册全局 TracerProvider 与 W3C 传播器，返回的 shutdown 在退出前刷新未导出的 span；
+// 未启用时保持 OTel 默认的 noop 实现
+func Setup(ctx context.Context, cfg config.TracingConfig) (func(context.Context) error, error) {
+	if !cfg.Enabled {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	var (
+		exporter sdktrace.SpanExporter
+		err      error
+	)
+	switch cfg.Exporter {
+	case "stdout":
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout), stdouttrace.WithPrettyPrint())
+	default:
+		opts := []otlptracehttp.Option{}
+		if cfg.Endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpointURL(cfg.Endpoint))
+		}
+		if cfg.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		exporter, err = otlptracehttp.New(ctx, opts...)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("create %s trace exporter failed: %w", cfg.Exporter, err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(cfg.ServiceName),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("build trace resource failed: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	enabled.Store(true)
+	return provider.Shutdown, nil
+}
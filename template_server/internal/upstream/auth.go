@@ -0,0 +1,82 @@
+package upstream
+
+import (
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"appbox/appbox_server/pkg/gatewayauth"
+)
+
+const (
+	AuthModeHeader = "header"
+	AuthModeHMAC   = "hmac"
+)
+
+type KeySource interface {
+	Value() (string, error)
+}
+
+// Auth 描述服务间鉴权：Keys 依次为主密钥与轮换期间的备用密钥；
+// Mode 为 hmac 时密钥仅用于签名，不再以明文请求头发送
+type Auth struct {
+	Mode   string
+	Header string
+	Keys   []KeySource
+}
+
+// keyRing 记录当前优先使用的密钥；上游以 401 拒绝时切换到下一把密钥重试一次，成功后固定使用该密钥
+type keyRing struct {
+	mode      string
+	header    string
+	keys      []KeySource
+	preferred atomic.Int32
+}
+
+func newKeyRing(auth Auth) *keyRing {
+	keys := make([]KeySource, 0, len(auth.Keys))
+	for _, key := range auth.Keys {
+		if key != nil {
+			keys = append(keys, key)
+		}
+	}
+	return &keyRing{mode: auth.Mode, header: auth.Header, keys: keys}
+}
+
+func (r *keyRing) size() int {
+	return len(r.keys)
+}
+
+func (r *keyRing) current() int {
+	return int(r.preferred.Load())
+}
+
+func (r *keyRing) next(index int) int {
+	return (index + 1) % len(r.keys)
+}
+
+func (r *keyRing) prefer(index int) {
+	r.preferred.Store(int32(index))
+}
+
+func (r *keyRing) apply(req *http.Request, payload []byte, index int) error {
+	if len(r.keys) == 0 {
+		return nil
+	}
+	if r.mode != AuthModeHMAC && r.header == "" {
+		return nil
+	}
+	value, err := r.keys[index].Value()
+	if err != nil {
+		return fmt.Errorf("resolve gateway key #%d failed: %w", index+1, err)
+	}
+	if r.mode == AuthModeHMAC {
+		if err := gatewayauth.Sign(req, payload, value, time.Now()); err != nil {
+			return fmt.Errorf("sign upstream request failed: %w", err)
+		}
+		return nil
+	}
+	req.Header.Set(r.header, value)
+	return nil
+}
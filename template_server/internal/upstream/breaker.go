@@ -0,0 +1,164 @@
+package upstream
+
+import (
+	"sync"
+	"time"
+
+	"appbox/appbox_server/pkg/logger"
+)
+
+type BreakerState string
+
+const (
+	BreakerDisabled BreakerState = "disabled"
+	BreakerClosed   BreakerState = "closed"
+	BreakerOpen     BreakerState = "open"
+	BreakerHalfOpen BreakerState = "half-open"
+)
+
+type BreakerConfig struct {
+	Enabled          bool
+	FailureRatio     float64
+	MinRequests      int
+	Window           time.Duration
+	CoolDown         time.Duration
+	HalfOpenRequests int
+}
+
+// Breaker 按固定统计窗口计算失败率：closed 状态下失败率达到阈值后进入 open，
+// open 持续 CoolDown 后进入 half-open 放行少量探测请求，探测全部成功则恢复 closed，任一失败重新 open。
+type Breaker struct {
+	name             string
+	mu               sync.Mutex
+	cfg              BreakerConfig
+	state            BreakerState
+	windowStart      time.Time
+	requests         int
+	failures         int
+	openedAt         time.Time
+	halfOpenInFlight int
+	halfOpenPassed   int
+	now              func() time.Time
+}
+
+func NewBreaker(name string, cfg BreakerConfig) *Breaker {
+	if cfg.MinRequests <= 0 {
+		cfg.MinRequests = 1
+	}
+	if cfg.HalfOpenRequests <= 0 {
+		cfg.HalfOpenRequests = 1
+	}
+	return &Breaker{
+		name:  name,
+		cfg:   cfg,
+		state: BreakerClosed,
+		now:   time.Now,
+	}
+}
+
+func (b *Breaker) State() BreakerState {
+	if b == nil || !b.cfg.Enabled {
+		return BreakerDisabled
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.advance(b.now())
+	return b.state
+}
+
+// Allow 判断当前是否放行请求，放行后调用方必须通过 Record 回报结果，或通过 Release 归还名额
+func (b *Breaker) Allow() bool {
+	if b == nil || !b.cfg.Enabled {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.advance(b.now())
+	switch b.state {
+	case BreakerOpen:
+		return false
+	case BreakerHalfOpen:
+		if b.halfOpenInFlight+b.halfOpenPassed >= b.cfg.HalfOpenRequests {
+			return false
+		}
+		b.halfOpenInFlight++
+	}
+	return true
+}
+
+func (b *Breaker) Record(success bool) {
+	if b == nil || !b.cfg.Enabled {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	b.advance(now)
+	switch b.state {
+	case BreakerHalfOpen:
+		if b.halfOpenInFlight > 0 {
+			b.halfOpenInFlight--
+		}
+		if !success {
+			b.open(now)
+			return
+		}
+		b.halfOpenPassed++
+		if b.halfOpenPassed >= b.cfg.HalfOpenRequests {
+			b.reset(BreakerClosed, now)
+		}
+	case BreakerClosed:
+		b.requests++
+		if !success {
+			b.failures++
+		}
+		if b.requests >= b.cfg.MinRequests && float64(b.failures)/float64(b.requests) >= b.cfg.FailureRatio {
+			b.open(now)
+		}
+	}
+}
+
+// Release 归还 Allow 放行的名额但不计入成功或失败，用于调用方主动取消的请求
+func (b *Breaker) Release() {
+	if b == nil || !b.cfg.Enabled {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == BreakerHalfOpen && b.halfOpenInFlight > 0 {
+		b.halfOpenInFlight--
+	}
+}
+
+func (b *Breaker) advance(now time.Time) {
+	switch b.state {
+	case BreakerOpen:
+		if now.Sub(b.openedAt) >= b.cfg.CoolDown {
+			b.reset(BreakerHalfOpen, now)
+		}
+	case BreakerClosed:
+		if b.cfg.Window > 0 && now.Sub(b.windowStart) >= b.cfg.Window {
+			b.reset(BreakerClosed, now)
+		}
+	}
+}
+
+func (b *Breaker) open(now time.Time) {
+	b.reset(BreakerOpen, now)
+	b.openedAt = now
+}
+
+func (b *Breaker) reset(state BreakerState, now time.Time) {
+	if state != b.state {
+		logger.Warnf("upstream %s circuit breaker: %s -> %s", b.name, b.state, state)
+	}
+	b.state = state
+	b.windowStart = now
+	b.requests = 0
+	b.failures = 0
+	b.halfOpenInFlight = 0
+	b.halfOpenPassed = 0
+}
@@ -0,0 +1,67 @@
+package upstream
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+type BulkheadConfig struct {
+	MaxInFlight int
+	MaxWait     time.Duration
+}
+
+// Bulkhead 限制单个上游同时进行中的请求数，避免一个慢上游占满网关的连接与 goroutine；
+// 名额已满时最多等待 MaxWait，仍无空位则直接返回 503
+type Bulkhead struct {
+	name    string
+	slots   chan struct{}
+	maxWait time.Duration
+}
+
+func NewBulkhead(name string, cfg BulkheadConfig) *Bulkhead {
+	b := &Bulkhead{name: name, maxWait: cfg.MaxWait}
+	if cfg.MaxInFlight > 0 {
+		b.slots = make(chan struct{}, cfg.MaxInFlight)
+	}
+	return b
+}
+
+// Acquire 获取一个名额，成功时返回的 release 必须被调用
+func (b *Bulkhead) Acquire(ctx context.Context) (func(), error) {
+	if b == nil || b.slots == nil {
+		return func() {}, nil
+	}
+	release := func() { <-b.slots }
+
+	select {
+	case b.slots <- struct{}{}:
+		return release, nil
+	default:
+	}
+	if b.maxWait > 0 {
+		timer := time.NewTimer(b.maxWait)
+		defer timer.Stop()
+		select {
+		case b.slots <- struct{}{}:
+			return release, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+	return nil, &Error{
+		StatusCode: http.StatusServiceUnavailable,
+		Message:    fmt.Sprintf("upstream %s is busy: concurrency limit %d reached", b.name, cap(b.slots)),
+		Kind:       ResultBusy,
+	}
+}
+
+// InFlight 返回当前进行中的请求数，未限制并发时恒为 0
+func (b *Bulkhead) InFlight() int {
+	if b == nil || b.slots == nil {
+		return 0
+	}
+	return len(b.slots)
+}
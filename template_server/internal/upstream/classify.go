@@ -0,0 +1,69 @@
+package upstream
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+)
+
+// 调用结果分类，用作指标的 result 标签
+const (
+	ResultOK          = "ok"
+	ResultClientError = "client_error"
+	ResultServerError = "server_error"
+	ResultTimeout     = "timeout"
+	ResultConnection  = "connection"
+	ResultCanceled    = "canceled"
+	ResultCircuitOpen = "circuit_open"
+	ResultBusy        = "busy"
+	ResultError       = "error"
+)
+
+// Classify 将 DoJSON 等调用返回的错误归类，nil 视为成功
+func Classify(err error) string {
+	if err == nil {
+		return ResultOK
+	}
+	var upErr *Error
+	if errors.As(err, &upErr) {
+		if upErr.Kind != "" {
+			return upErr.Kind
+		}
+		return classifyStatus(upErr.StatusCode)
+	}
+	return classifyTransport(err)
+}
+
+func classifyAttempt(statusCode int, err error) string {
+	if err != nil {
+		return classifyTransport(err)
+	}
+	return classifyStatus(statusCode)
+}
+
+func classifyStatus(statusCode int) string {
+	switch {
+	case statusCode >= http.StatusInternalServerError:
+		return ResultServerError
+	case statusCode >= http.StatusBadRequest:
+		return ResultClientError
+	default:
+		return ResultOK
+	}
+}
+
+func classifyTransport(err error) string {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.Canceled):
+		return ResultCanceled
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return ResultTimeout
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return ResultConnection
+	}
+	return ResultError
+}
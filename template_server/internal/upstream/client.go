@@ -0,0 +1,316 @@
+package upstream
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.43.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"appbox/appbox_server/internal/metrics"
+	"appbox/appbox_server/internal/requestctx"
+	"appbox/appbox_server/internal/tracing"
+	"appbox/appbox_server/pkg/logger"
+)
+
+type Config struct {
+	Name      string
+	BaseURL   string
+	Timeout   time.Duration
+	TLS       *tls.Config
+	Transport TransportConfig
+	Auth      Auth
+	Retry     RetryPolicy
+	Breaker   BreakerConfig
+	Bulkhead  BulkheadConfig
+}
+
+type Client struct {
+	name       string
+	baseURL    string
+	auth       *keyRing
+	retry      RetryPolicy
+	breaker    *Breaker
+	bulkhead   *Bulkhead
+	httpClient *http.Client
+}
+
+type response[T any] struct {
+	Code      int    `json:"code"`
+	Timestamp int64  `json:"timestamp"`
+	Msg       string `json:"msg"`
+	Data      T      `json:"data"`
+}
+
+func NewClient(cfg Config) *Client {
+	return &Client{
+		name:     cfg.Name,
+		baseURL:  strings.TrimRight(cfg.BaseURL, "/"),
+		auth:     newKeyRing(cfg.Auth),
+		retry:    cfg.Retry,
+		breaker:  NewBreaker(cfg.Name, cfg.Breaker),
+		bulkhead: NewBulkhead(cfg.Name, cfg.Bulkhead),
+		httpClient: &http.Client{
+			Timeout:   cfg.Timeout,
+			Transport: newTransport(cfg),
+		},
+	}
+}
+
+func (c *Client) DoJSON(ctx context.Context, method, path string, reqBody interface{}, out interface{}) error {
+	var payload []byte
+	if reqBody != nil {
+		encoded, err := json.Marshal(reqBody)
+		if err != nil {
+			return fmt.Errorf("marshal request failed: %w", err)
+		}
+		payload = encoded
+	}
+
+	release, err := c.bulkhead.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+	inFlight := metrics.UpstreamInFlight.WithLabelValues(c.name)
+	inFlight.Inc()
+	defer inFlight.Dec()
+
+	maxAttempts := c.retry.attempts(method)
+	var (
+		statusCode int
+		raw        []byte
+	)
+	for attempt := 1; ; attempt++ {
+		if !c.breaker.Allow() {
+			if attempt == 1 {
+				return &Error{
+					StatusCode: http.StatusServiceUnavailable,
+					Message:    fmt.Sprintf("upstream %s is unavailable: circuit breaker is open", c.name),
+					Kind:       ResultCircuitOpen,
+				}
+			}
+			logger.FromContext(ctx).Warn("upstream retry aborted: circuit breaker is open",
+				"upstream", c.name, "method", method, "path", path, "attempts", attempt-1)
+			return finish(statusCode, raw, err, out)
+		}
+
+		statusCode, raw, err = c.send(ctx, method, path, payload)
+		// 调用方取消（客户端断开、搜索提前结束）不反映上游健康状况，不计入熔断统计
+		if errors.Is(err, context.Canceled) {
+			c.breaker.Release()
+		} else {
+			c.breaker.Record(err == nil && statusCode < http.StatusInternalServerError)
+		}
+
+		retryable := c.retry.retryableStatus(statusCode)
+		if err != nil {
+			retryable = c.retry.retryableError(err)
+		}
+		if !retryable || attempt >= maxAttempts {
+			if attempt > 1 {
+				logger.FromContext(ctx).Info("upstream call finished after retries",
+					"upstream", c.name, "method", method, "path", path, "attempts", attempt, "status", statusCode)
+			}
+			return finish(statusCode, raw, err, out)
+		}
+
+		delay := c.retry.backoff(attempt)
+		reason := fmt.Sprintf("status=%d", statusCode)
+		if err != nil {
+			reason = err.Error()
+		}
+		logger.FromContext(ctx).Warn("upstream attempt failed, retrying",
+			"upstream", c.name, "method", method, "path", path, "attempt", attempt, "max_attempts", maxAttempts,
+			"reason", reason, "backoff", delay.String())
+		if sleepErr := sleepContext(ctx, delay); sleepErr != nil {
+			return finish(statusCode, raw, err, out)
+		}
+	}
+}
+
+type ProbeResult struct {
+	Latency time.Duration
+	Version string
+}
+
+// Probe 直接请求上游健康检查地址，不经过重试与熔断，非 2xx 视为失败；
+// 若响应为统一信封且 data.version 存在，则一并返回上游版本号
+func (c *Client) Probe(ctx context.Context, path string) (ProbeResult, error) {
+	start := time.Now()
+	statusCode, raw, err := c.send(ctx, http.MethodGet, path, nil)
+	result := ProbeResult{Latency: time.Since(start)}
+	if err != nil {
+		return result, err
+	}
+	if statusCode < 200 || statusCode >= 300 {
+		return result, &Error{StatusCode: statusCode, Message: fmt.Sprintf("health probe failed: status=%d", statusCode)}
+	}
+
+	var wrapped response[struct {
+		Version string `json:"version"`
+	}]
+	if json.Unmarshal(raw, &wrapped) == nil {
+		result.Version = strings.TrimSpace(wrapped.Data.Version)
+	}
+	return result, nil
+}
+
+// InFlight 返回当前占用 bulkhead 名额的请求数
+func (c *Client) InFlight() int {
+	return c.bulkhead.InFlight()
+}
+
+func (c *Client) BreakerState() BreakerState {
+	return c.breaker.State()
+}
+
+// send 发起一次上游请求；若配置了备用密钥且上游返回 401，则立即换用下一把密钥重试一次
+func (c *Client) send(ctx context.Context, method, path string, payload []byte) (int, []byte, error) {
+	stats := CallStatsFromContext(ctx)
+	keyIndex := c.auth.current()
+	statusCode, raw, err := c.do(ctx, method, path, payload, keyIndex)
+	stats.recordAttempt(statusCode)
+	if err != nil || statusCode != http.StatusUnauthorized || c.auth.size() < 2 {
+		return statusCode, raw, err
+	}
+
+	nextIndex := c.auth.next(keyIndex)
+	logger.FromContext(ctx).Warn("upstream rejected gateway key with 401, retrying with next key",
+		"upstream", c.name, "method", method, "path", path, "key", keyIndex+1, "next_key", nextIndex+1)
+	statusCode, raw, err = c.do(ctx, method, path, payload, nextIndex)
+	stats.recordAttempt(statusCode)
+	if err == nil && statusCode != http.StatusUnauthorized {
+		c.auth.prefer(nextIndex)
+		logger.FromContext(ctx).Info("upstream switched gateway key", "upstream", c.name, "key", nextIndex+1)
+	}
+	return statusCode, raw, err
+}
+
+// do 发起一次上游请求；每次尝试（含重试与换密钥重发）对应一个 client span 与一次 attempt 指标
+func (c *Client) do(ctx context.Context, method, path string, payload []byte, keyIndex int) (int, []byte, error) {
+	fullURL := c.baseURL + "/" + strings.TrimPrefix(path, "/")
+	ctx, span := tracing.Tracer().Start(ctx, "HTTP "+method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.HTTPRequestMethodKey.String(method),
+			semconv.URLFull(fullURL),
+			tracing.AttrProvider.String(c.name),
+		),
+	)
+	defer span.End()
+
+	start := time.Now()
+	statusCode, raw, err := c.exchange(ctx, method, fullURL, payload, keyIndex)
+	metrics.UpstreamDuration.WithLabelValues(c.name, method).Observe(time.Since(start).Seconds())
+	metrics.UpstreamAttempts.WithLabelValues(c.name, method, classifyAttempt(statusCode, err)).Inc()
+	if statusCode > 0 {
+		span.SetAttributes(semconv.HTTPResponseStatusCode(statusCode))
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	} else if statusCode >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, http.StatusText(statusCode))
+	}
+	return statusCode, raw, err
+}
+
+func (c *Client) exchange(ctx context.Context, method, fullURL string, payload []byte, keyIndex int) (int, []byte, error) {
+
+	var bodyReader io.Reader
+	if payload != nil {
+		bodyReader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, fullURL, bodyReader)
+	if err != nil {
+		return 0, nil, fmt.Errorf("build request failed: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if id := requestctx.RequestID(ctx); id != "" {
+		req.Header.Set(requestctx.HeaderRequestID, id)
+	}
+	if tracing.Enabled() {
+		otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+	} else if tc, ok := requestctx.Trace(ctx); ok {
+		req.Header.Set(requestctx.HeaderTraceParent, tc.Child().TraceParent())
+		if tc.State != "" {
+			req.Header.Set(requestctx.HeaderTraceState, tc.State)
+		}
+	}
+	if err := c.auth.apply(req, payload, keyIndex); err != nil {
+		return 0, nil, err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, nil, fmt.Errorf("request upstream failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, nil, fmt.Errorf("read upstream response failed: %w", err)
+	}
+	return resp.StatusCode, raw, nil
+}
+
+func finish(statusCode int, raw []byte, err error, out interface{}) error {
+	if err != nil {
+		return err
+	}
+	return decodeResponse(statusCode, raw, out)
+}
+
+func decodeResponse(statusCode int, raw []byte, out interface{}) error {
+	var wrapped response[json.RawMessage]
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &wrapped); err != nil {
+			if statusCode >= 200 && statusCode < 300 {
+				return fmt.Errorf("upstream response is not json: %s", string(raw))
+			}
+			return &Error{StatusCode: statusCode, Message: string(raw)}
+		}
+	}
+
+	if statusCode < 200 || statusCode >= 300 || wrapped.Code != http.StatusOK {
+		msg := strings.TrimSpace(wrapped.Msg)
+		if msg == "" {
+			msg = fmt.Sprintf("upstream request failed: status=%d", statusCode)
+		}
+		code := statusCode
+		if code == 0 {
+			code = wrapped.Code
+		}
+		if code == 0 {
+			code = http.StatusBadGateway
+		}
+		return &Error{StatusCode: code, Message: msg}
+	}
+
+	if out == nil {
+		return nil
+	}
+	if len(wrapped.Data) == 0 || string(wrapped.Data) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(wrapped.Data, out); err != nil {
+		return fmt.Errorf("unmarshal upstream data failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,20 @@
+package upstream
+
+import "fmt"
+
+// Error 为上游调用失败；Kind 标记网关侧主动拒绝的原因（如 circuit_open、busy），上游返回的错误为空
+type Error struct {
+	StatusCode int
+	Message    string
+	Kind       string
+}
+
+func (e *Error) Error() string {
+	if e == nil {
+		return "upstream error"
+	}
+	if e.Message == "" {
+		return fmt.Sprintf("upstream status=%d", e.StatusCode)
+	}
+	return e.Message
+}
@@ -0,0 +1,81 @@
+package upstream
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"strings"
+	"syscall"
+	"time"
+)
+
+type RetryPolicy struct {
+	MaxAttempts       int
+	InitialBackoff    time.Duration
+	MaxBackoff        time.Duration
+	Jitter            float64
+	RetryOnStatus     []int
+	IdempotentMethods []string
+}
+
+func (p RetryPolicy) attempts(method string) int {
+	if p.MaxAttempts <= 1 || !p.allowsMethod(method) {
+		return 1
+	}
+	return p.MaxAttempts
+}
+
+func (p RetryPolicy) allowsMethod(method string) bool {
+	for _, allowed := range p.IdempotentMethods {
+		if strings.EqualFold(strings.TrimSpace(allowed), method) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p RetryPolicy) retryableStatus(statusCode int) bool {
+	for _, code := range p.RetryOnStatus {
+		if code == statusCode {
+			return true
+		}
+	}
+	return false
+}
+
+func (p RetryPolicy) retryableError(err error) bool {
+	return errors.Is(err, syscall.ECONNREFUSED)
+}
+
+// backoff 返回第 attempt 次失败后的等待时长，按指数增长并叠加 ±Jitter 比例的随机抖动
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	if p.InitialBackoff <= 0 {
+		return 0
+	}
+	delay := p.InitialBackoff << uint(attempt-1)
+	if delay <= 0 || (p.MaxBackoff > 0 && delay > p.MaxBackoff) {
+		delay = p.MaxBackoff
+	}
+	if p.Jitter > 0 {
+		spread := float64(delay) * p.Jitter
+		delay += time.Duration((rand.Float64()*2 - 1) * spread)
+	}
+	if delay < 0 {
+		return 0
+	}
+	return delay
+}
+
+func sleepContext(ctx context.Context, delay time.Duration) error {
+	if delay <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,53 @@
+package upstream
+
+import (
+	"context"
+	"sync"
+)
+
+type callStatsKey struct{}
+
+// CallStats 记录单个入站请求期间上游调用的尝试次数与最后一次状态码
+type CallStats struct {
+	mu         sync.Mutex
+	attempts   int
+	statusCode int
+}
+
+func WithCallStats(ctx context.Context) (context.Context, *CallStats) {
+	stats := &CallStats{}
+	return context.WithValue(ctx, callStatsKey{}, stats), stats
+}
+
+func CallStatsFromContext(ctx context.Context) *CallStats {
+	stats, _ := ctx.Value(callStatsKey{}).(*CallStats)
+	return stats
+}
+
+func (s *CallStats) Attempts() int {
+	if s == nil {
+		return 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.attempts
+}
+
+func (s *CallStats) StatusCode() int {
+	if s == nil {
+		return 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.statusCode
+}
+
+func (s *CallStats) recordAttempt(statusCode int) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attempts++
+	s.statusCode = statusCode
+}
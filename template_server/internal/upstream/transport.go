@@ -0,0 +1,67 @@
+package upstream
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"time"
+)
+
+// TransportConfig 为单个上游的连接池与超时设置，零值字段沿用 http.DefaultTransport 的默认值
+type TransportConfig struct {
+	MaxIdleConns          int
+	MaxIdleConnsPerHost   int
+	MaxConnsPerHost       int
+	DialTimeout           time.Duration
+	KeepAlive             time.Duration
+	TLSHandshakeTimeout   time.Duration
+	ResponseHeaderTimeout time.Duration
+	IdleConnTimeout       time.Duration
+	DisableKeepAlives     bool
+	HTTP2                 bool
+}
+
+// newTransport 为每个上游构建独立 transport，避免不同 provider 的 TLS 与连接池设置互相影响
+func newTransport(cfg Config) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if cfg.TLS != nil {
+		transport.TLSClientConfig = cfg.TLS
+	}
+
+	t := cfg.Transport
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	if t.DialTimeout > 0 {
+		dialer.Timeout = t.DialTimeout
+	}
+	if t.KeepAlive > 0 {
+		dialer.KeepAlive = t.KeepAlive
+	}
+	transport.DialContext = dialer.DialContext
+
+	if t.MaxIdleConns > 0 {
+		transport.MaxIdleConns = t.MaxIdleConns
+	}
+	if t.MaxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = t.MaxIdleConnsPerHost
+	}
+	if t.MaxConnsPerHost > 0 {
+		transport.MaxConnsPerHost = t.MaxConnsPerHost
+	}
+	if t.TLSHandshakeTimeout > 0 {
+		transport.TLSHandshakeTimeout = t.TLSHandshakeTimeout
+	}
+	if t.ResponseHeaderTimeout > 0 {
+		transport.ResponseHeaderTimeout = t.ResponseHeaderTimeout
+	}
+	if t.IdleConnTimeout > 0 {
+		transport.IdleConnTimeout = t.IdleConnTimeout
+	}
+	transport.DisableKeepAlives = t.DisableKeepAlives
+
+	transport.ForceAttemptHTTP2 = t.HTTP2
+	if !t.HTTP2 {
+		// 非 nil 的空 TLSNextProto 会关闭 ALPN 协商出的 HTTP/2
+		transport.TLSNextProto = make(map[string]func(string, *tls.Conn) http.RoundTripper)
+	}
+	return transport
+}
@@ -0,0 +1,193 @@
+// Package gatewayauth 实现 appbox 网关与 app_server 之间的 HMAC-SHA256 请求签名。
+//
+// 网关对 method、请求路径（含 query）、时间戳、随机 nonce 与请求体 SHA-256 组成的规范串签名，
+// app_server 通过 Verifier 校验签名、时间窗口与 nonce 重放，可直接使用 Middleware 包装 net/http 处理器，
+// gin 等基于 net/http 的框架可在中间件中调用 Verifier.Verify(c.Request)，fiber 需经 adaptor 转换。
+package gatewayauth
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	HeaderTimestamp = "X-Gateway-Timestamp"
+	HeaderNonce     = "X-Gateway-Nonce"
+	HeaderSignature = "X-Gateway-Signature"
+
+	DefaultMaxSkew = 5 * time.Minute
+	// DefaultMaxBodyBytes 为校验时读取请求体的默认上限
+	DefaultMaxBodyBytes int64 = 10 << 20
+)
+
+var (
+	ErrMissingHeaders   = errors.New("gateway signature headers are required")
+	ErrInvalidTimestamp = errors.New("invalid gateway timestamp")
+	ErrExpired          = errors.New("gateway signature expired")
+	ErrReplayed         = errors.New("gateway nonce already used")
+	ErrInvalidSignature = errors.New("invalid gateway signature")
+	ErrNoKeys           = errors.New("gateway auth key is not configured")
+	ErrBodyTooLarge     = errors.New("request body too large")
+)
+
+// CanonicalString 返回参与签名的规范串，网关与 app_server 必须保持一致
+func CanonicalString(method, requestURI, timestamp, nonce string, body []byte) string {
+	bodyHash := sha256.Sum256(body)
+	return strings.Join([]string{
+		strings.ToUpper(method),
+		requestURI,
+		timestamp,
+		nonce,
+		hex.EncodeToString(bodyHash[:]),
+	}, "\n")
+}
+
+func Signature(key, canonical string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(canonical))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Sign 为请求写入时间戳、nonce 与签名头，body 必须与实际发送的请求体一致
+func Sign(req *http.Request, body []byte, key string, now time.Time) error {
+	nonceBytes := make([]byte, 16)
+	if _, err := rand.Read(nonceBytes); err != nil {
+		return err
+	}
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	nonce := hex.EncodeToString(nonceBytes)
+
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderNonce, nonce)
+	req.Header.Set(HeaderSignature, Signature(key, CanonicalString(req.Method, req.URL.RequestURI(), timestamp, nonce, body)))
+	return nil
+}
+
+// Verifier 校验网关签名；Keys 可同时配置新旧密钥以支持轮换，MaxBodyBytes 限制校验时读取的请求体大小
+type Verifier struct {
+	Keys         []string
+	MaxSkew      time.Duration
+	MaxBodyBytes int64
+	Nonces       *NonceCache
+	Now          func() time.Time
+}
+
+func NewVerifier(keys ...string) *Verifier {
+	return &Verifier{
+		Keys:         keys,
+		MaxSkew:      DefaultMaxSkew,
+		MaxBodyBytes: DefaultMaxBodyBytes,
+		Nonces:       NewNonceCache(),
+		Now:          time.Now,
+	}
+}
+
+// Verify 校验请求签名，会读取并还原 r.Body 以便后续处理器继续使用；请求体超过 MaxBodyBytes 时返回 ErrBodyTooLarge
+func (v *Verifier) Verify(r *http.Request) error {
+	keys := make([]string, 0, len(v.Keys))
+	for _, key := range v.Keys {
+		if strings.TrimSpace(key) != "" {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return ErrNoKeys
+	}
+
+	timestamp := r.Header.Get(HeaderTimestamp)
+	nonce := r.Header.Get(HeaderNonce)
+	signature := r.Header.Get(HeaderSignature)
+	if timestamp == "" || nonce == "" || signature == "" {
+		return ErrMissingHeaders
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidTimestamp
+	}
+	now := time.Now
+	if v.Now != nil {
+		now = v.Now
+	}
+	maxSkew := v.MaxSkew
+	if maxSkew <= 0 {
+		maxSkew = DefaultMaxSkew
+	}
+	skew := now().Sub(time.Unix(unix, 0))
+	if skew > maxSkew || skew < -maxSkew {
+		return ErrExpired
+	}
+
+	maxBody := v.MaxBodyBytes
+	if maxBody <= 0 {
+		maxBody = DefaultMaxBodyBytes
+	}
+	var body []byte
+	if r.Body != nil {
+		body, err = io.ReadAll(io.LimitReader(r.Body, maxBody+1))
+		if err != nil {
+			return err
+		}
+		if int64(len(body)) > maxBody {
+			return ErrBodyTooLarge
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	canonical := CanonicalString(r.Method, r.URL.RequestURI(), timestamp, nonce, body)
+	matched := false
+	for _, key := range keys {
+		if hmac.Equal([]byte(Signature(key, canonical)), []byte(signature)) {
+			matched = true
+			break
+		}
+	}
+	if !matched {
+		return ErrInvalidSignature
+	}
+
+	if v.Nonces != nil && !v.Nonces.Add(nonce, time.Unix(unix, 0).Add(maxSkew)) {
+		return ErrReplayed
+	}
+	return nil
+}
+
+// errorResponse 与网关统一响应结构的字段保持一致
+type errorResponse struct {
+	Code      int    `json:"code"`
+	Timestamp int64  `json:"timestamp"`
+	Msg       string `json:"msg"`
+}
+
+// Middleware 包装 net/http 处理器，校验失败时按统一响应结构返回 401（未配置密钥时返回 503，请求体超限时返回 413）
+func Middleware(v *Verifier) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := v.Verify(r); err != nil {
+				status := http.StatusUnauthorized
+				switch {
+				case errors.Is(err, ErrNoKeys):
+					status = http.StatusServiceUnavailable
+				case errors.Is(err, ErrBodyTooLarge):
+					status = http.StatusRequestEntityTooLarge
+				}
+				body, _ := json.Marshal(errorResponse{Code: status, Timestamp: time.Now().UnixMilli(), Msg: err.Error()})
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(status)
+				_, _ = w.Write(body)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,225 @@
+package gatewayauth
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+const testKey = "test-gateway-key"
+
+var testNow = time.Unix(1767225600, 0)
+
+func newSignedRequest(t *testing.T, method, target string, body []byte, key string, signedAt time.Time) *http.Request {
+	t.Helper()
+	req := httptest.NewRequest(method, target, bytes.NewReader(body))
+	if err := Sign(req, body, key, signedAt); err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	return req
+}
+
+func newTestVerifier(keys ...string) *Verifier {
+	v := NewVerifier(keys...)
+	v.Now = func() time.Time { return testNow }
+	v.Nonces.now = v.Now
+	return v
+}
+
+func TestCanonicalString(t *testing.T) {
+	got := CanonicalString("put", "/api/v1/admin/configs/theme?app=stellar", "1767225600", "abc123", []byte(`{"configValue":"dark"}`))
+	want := "PUT\n/api/v1/admin/configs/theme?app=stellar\n1767225600\nabc123\n" +
+		"feb5812da3e7c708f05a8e896f257f0b2eadb4ed1f296bf8baf3d35cc3cfa151"
+	if got != want {
+		t.Fatalf("CanonicalString() = %q, want %q", got, want)
+	}
+
+	empty := CanonicalString("GET", "/api/v1/admin/users", "1", "n", nil)
+	if want := "GET\n/api/v1/admin/users\n1\nn\ne3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"; empty != want {
+		t.Fatalf("CanonicalString() with empty body = %q, want %q", empty, want)
+	}
+}
+
+func TestSignThenVerify(t *testing.T) {
+	body := []byte(`{"status":"disabled"}`)
+	req := newSignedRequest(t, http.MethodPut, "/api/v1/admin/users/42?app=stellar", body, testKey, testNow)
+
+	if err := newTestVerifier(testKey).Verify(req); err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	restored, err := io.ReadAll(req.Body)
+	if err != nil {
+		t.Fatalf("read restored body: %v", err)
+	}
+	if !bytes.Equal(restored, body) {
+		t.Fatalf("restored body = %q, want %q", restored, body)
+	}
+}
+
+func TestVerifyRejectsTamperedRequest(t *testing.T) {
+	body := []byte(`{"status":"active"}`)
+	tests := []struct {
+		name   string
+		tamper func(req *http.Request)
+	}{
+		{"body", func(req *http.Request) {
+			req.Body = io.NopCloser(bytes.NewReader([]byte(`{"status":"disabled"}`)))
+		}},
+		{"method", func(req *http.Request) { req.Method = http.MethodDelete }},
+		{"query", func(req *http.Request) { req.URL.RawQuery = "app=tinytext" }},
+		{"timestamp", func(req *http.Request) {
+			req.Header.Set(HeaderTimestamp, strconv.FormatInt(testNow.Unix()+1, 10))
+		}},
+		{"nonce", func(req *http.Request) { req.Header.Set(HeaderNonce, "another-nonce") }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := newSignedRequest(t, http.MethodPut, "/api/v1/admin/users/42?app=stellar", body, testKey, testNow)
+			tt.tamper(req)
+			if err := newTestVerifier(testKey).Verify(req); !errors.Is(err, ErrInvalidSignature) {
+				t.Fatalf("Verify() error = %v, want %v", err, ErrInvalidSignature)
+			}
+		})
+	}
+}
+
+func TestVerifySkewWindow(t *testing.T) {
+	tests := []struct {
+		name     string
+		signedAt time.Time
+		want     error
+	}{
+		{"within past window", testNow.Add(-DefaultMaxSkew + time.Second), nil},
+		{"within future window", testNow.Add(DefaultMaxSkew - time.Second), nil},
+		{"too old", testNow.Add(-DefaultMaxSkew - time.Second), ErrExpired},
+		{"too far in future", testNow.Add(DefaultMaxSkew + time.Second), ErrExpired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := newSignedRequest(t, http.MethodGet, "/api/v1/admin/users", nil, testKey, tt.signedAt)
+			if err := newTestVerifier(testKey).Verify(req); !errors.Is(err, tt.want) {
+				t.Fatalf("Verify() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyRejectsReplayedNonce(t *testing.T) {
+	v := newTestVerifier(testKey)
+	req := newSignedRequest(t, http.MethodDelete, "/api/v1/admin/users/42", nil, testKey, testNow)
+	if err := v.Verify(req); err != nil {
+		t.Fatalf("first Verify() error = %v", err)
+	}
+
+	replay := httptest.NewRequest(http.MethodDelete, "/api/v1/admin/users/42", nil)
+	replay.Header = req.Header.Clone()
+	if err := v.Verify(replay); !errors.Is(err, ErrReplayed) {
+		t.Fatalf("replayed Verify() error = %v, want %v", err, ErrReplayed)
+	}
+}
+
+func TestVerifyRotatingKeys(t *testing.T) {
+	v := newTestVerifier("new-key", "", "old-key")
+	for _, key := range []string{"new-key", "old-key"} {
+		req := newSignedRequest(t, http.MethodGet, "/api/v1/admin/configs", nil, key, testNow)
+		if err := v.Verify(req); err != nil {
+			t.Fatalf("Verify() with %s error = %v", key, err)
+		}
+	}
+
+	req := newSignedRequest(t, http.MethodGet, "/api/v1/admin/configs", nil, "retired-key", testNow)
+	if err := v.Verify(req); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("Verify() with retired key error = %v, want %v", err, ErrInvalidSignature)
+	}
+}
+
+func TestVerifyHeaderErrors(t *testing.T) {
+	unsigned := httptest.NewRequest(http.MethodGet, "/api/v1/admin/users", nil)
+	if err := newTestVerifier(testKey).Verify(unsigned); !errors.Is(err, ErrMissingHeaders) {
+		t.Fatalf("Verify() without headers error = %v, want %v", err, ErrMissingHeaders)
+	}
+
+	badTimestamp := newSignedRequest(t, http.MethodGet, "/api/v1/admin/users", nil, testKey, testNow)
+	badTimestamp.Header.Set(HeaderTimestamp, "yesterday")
+	if err := newTestVerifier(testKey).Verify(badTimestamp); !errors.Is(err, ErrInvalidTimestamp) {
+		t.Fatalf("Verify() with bad timestamp error = %v, want %v", err, ErrInvalidTimestamp)
+	}
+
+	signed := newSignedRequest(t, http.MethodGet, "/api/v1/admin/users", nil, testKey, testNow)
+	if err := newTestVerifier("", " ").Verify(signed); !errors.Is(err, ErrNoKeys) {
+		t.Fatalf("Verify() without keys error = %v, want %v", err, ErrNoKeys)
+	}
+}
+
+func TestVerifyBodyLimit(t *testing.T) {
+	body := []byte(`{"configValue":"0123456789"}`)
+	v := newTestVerifier(testKey)
+	v.MaxBodyBytes = int64(len(body))
+	if err := v.Verify(newSignedRequest(t, http.MethodPut, "/api/v1/admin/configs/theme", body, testKey, testNow)); err != nil {
+		t.Fatalf("Verify() at limit error = %v", err)
+	}
+
+	v.MaxBodyBytes = int64(len(body)) - 1
+	if err := v.Verify(newSignedRequest(t, http.MethodPut, "/api/v1/admin/configs/theme", body, testKey, testNow)); !errors.Is(err, ErrBodyTooLarge) {
+		t.Fatalf("Verify() over limit error = %v, want %v", err, ErrBodyTooLarge)
+	}
+}
+
+func TestNonceCacheExpiry(t *testing.T) {
+	cache := NewNonceCache()
+	cache.now = func() time.Time { return testNow }
+	if !cache.Add("n1", testNow.Add(time.Minute)) {
+		t.Fatal("first Add() = false, want true")
+	}
+	if cache.Add("n1", testNow.Add(time.Minute)) {
+		t.Fatal("duplicate Add() = true, want false")
+	}
+
+	cache.now = func() time.Time { return testNow.Add(2 * time.Minute) }
+	if !cache.Add("n1", testNow.Add(3*time.Minute)) {
+		t.Fatal("Add() after expiry = false, want true")
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	body := []byte(`{"alias":"theme"}`)
+	var received []byte
+	handler := Middleware(newTestVerifier(testKey))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, newSignedRequest(t, http.MethodPut, "/api/v1/admin/configs/theme", body, testKey, testNow))
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("signed request status = %d, want %d", rec.Code, http.StatusNoContent)
+	}
+	if !bytes.Equal(received, body) {
+		t.Fatalf("handler body = %q, want %q", received, body)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/admin/users", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("unsigned request status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+	var resp errorResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("error body %q is not valid json: %v", rec.Body.String(), err)
+	}
+	if resp.Code != http.StatusUnauthorized || resp.Msg != ErrMissingHeaders.Error() {
+		t.Fatalf("error body = %+v, want code %d msg %q", resp, http.StatusUnauthorized, ErrMissingHeaders.Error())
+	}
+
+	rec = httptest.NewRecorder()
+	Middleware(newTestVerifier())(http.NotFoundHandler()).ServeHTTP(rec, newSignedRequest(t, http.MethodGet, "/api/v1/admin/users", nil, testKey, testNow))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status without keys = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+}
@@ -0,0 +1,38 @@
+package gatewayauth
+
+import (
+	"sync"
+	"time"
+)
+
+// NonceCache 在签名有效期内记录已使用的 nonce，用于拒绝重放请求；多实例部署时各实例独立记录
+type NonceCache struct {
+	mu      sync.Mutex
+	entries map[string]time.Time
+	now     func() time.Time
+}
+
+func NewNonceCache() *NonceCache {
+	return &NonceCache{
+		entries: make(map[string]time.Time),
+		now:     time.Now,
+	}
+}
+
+// Add 记录 nonce 直到 expiresAt，nonce 已存在且未过期时返回 false
+func (c *NonceCache) Add(nonce string, expiresAt time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	for key, expiry := range c.entries {
+		if now.After(expiry) {
+			delete(c.entries, key)
+		}
+	}
+	if expiry, ok := c.entries[nonce]; ok && !now.After(expiry) {
+		return false
+	}
+	c.entries[nonce] = expiresAt
+	return true
+}
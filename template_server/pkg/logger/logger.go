@@ -1,19 +1,133 @@
+// Package logger 基于 log/slog 提供分级结构化日志：支持 text / json 输出与运行期调整级别，
+// 并通过 context 携带附加了 request_id、provider 等字段的 logger。
 package logger
 
-import "log"
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+// 通用上下文字段名
+const (
+	KeyRequestID = "request_id"
+	KeyProvider  = "provider"
+	KeyOperation = "operation"
+	KeyUserID    = "user_id"
+	KeyOperator  = "operator"
+)
+
+type Config struct {
+	Level  string
+	Format string
+	Output io.Writer
+}
+
+type contextKey struct{}
+
+var (
+	level         = new(slog.LevelVar)
+	defaultLogger atomic.Pointer[slog.Logger]
+)
+
+func init() {
+	defaultLogger.Store(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level})))
+}
+
+// Setup 按配置替换默认 logger，Format 为 json 或 text（默认）
+func Setup(cfg Config) error {
+	if err := SetLevel(cfg.Level); err != nil {
+		return err
+	}
+	output := cfg.Output
+	if output == nil {
+		output = os.Stderr
+	}
+
+	opts := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	switch strings.ToLower(strings.TrimSpace(cfg.Format)) {
+	case "", "text":
+		handler = slog.NewTextHandler(output, opts)
+	case "json":
+		handler = slog.NewJSONHandler(output, opts)
+	default:
+		return fmt.Errorf("unknown log format %q", cfg.Format)
+	}
+
+	l := slog.New(handler)
+	defaultLogger.Store(l)
+	slog.SetDefault(l)
+	return nil
+}
+
+// SetLevel 调整日志级别（debug / info / warn / error），可在运行期调用
+func SetLevel(raw string) error {
+	parsed, err := ParseLevel(raw)
+	if err != nil {
+		return err
+	}
+	level.Set(parsed)
+	return nil
+}
+
+func ParseLevel(raw string) (slog.Level, error) {
+	var parsed slog.Level
+	value := strings.TrimSpace(raw)
+	if value == "" {
+		return slog.LevelInfo, nil
+	}
+	if err := parsed.UnmarshalText([]byte(value)); err != nil {
+		return slog.LevelInfo, fmt.Errorf("unknown log level %q", raw)
+	}
+	return parsed, nil
+}
+
+func Default() *slog.Logger {
+	return defaultLogger.Load()
+}
+
+// WithContext 将 logger 放入 context，供后续 FromContext 取出
+func WithContext(ctx context.Context, l *slog.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext 返回 context 中携带的 logger，没有时返回默认 logger
+func FromContext(ctx context.Context) *slog.Logger {
+	if ctx != nil {
+		if l, ok := ctx.Value(contextKey{}).(*slog.Logger); ok {
+			return l
+		}
+	}
+	return Default()
+}
+
+// With 在 context 中的 logger 上追加字段，如 logger.With(ctx, logger.KeyProvider, "stellar")
+func With(ctx context.Context, args ...any) context.Context {
+	return WithContext(ctx, FromContext(ctx).With(args...))
+}
+
+func Debugf(format string, args ...interface{}) {
+	Default().Debug(fmt.Sprintf(format, args...))
+}
 
 func Infof(format string, args ...interface{}) {
-	log.Printf("[INFO] "+format, args...)
+	Default().Info(fmt.Sprintf(format, args...))
 }
 
 func Warnf(format string, args ...interface{}) {
-	log.Printf("[WARN] "+format, args...)
+	Default().Warn(fmt.Sprintf(format, args...))
 }
 
 func Errorf(format string, args ...interface{}) {
-	log.Printf("[ERROR] "+format, args...)
+	Default().Error(fmt.Sprintf(format, args...))
 }
 
 func Fatalf(format string, args ...interface{}) {
-	log.Fatalf("[FATAL] "+format, args...)
+	Default().Error(fmt.Sprintf(format, args...), "fatal", true)
+	os.Exit(1)
 }
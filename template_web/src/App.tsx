@@ -1,5 +1,6 @@
 import { useEffect, useMemo, useState } from 'react';
 import { listProviders } from './api/admin';
+import type { ProviderItem } from './types/api';
 import {
   clearClientGateAccess,
   clearLegacyAuthState,
@@ -50,10 +51,32 @@ const appDefinitionMap: Record<string, AppDefinition> = {
   },
 };
 
-function resolveAvailableApps(providerKeys: string[]): AppDefinition[] {
-  return providerKeys
-    .map((providerKey) => appDefinitionMap[providerKey])
-    .filter((item): item is AppDefinition => Boolean(item));
+function resolveAvailableApps(providers: ProviderItem[]): AppDefinition[] {
+  return [...providers]
+    .sort((a, b) => Number(b.isDefault) - Number(a.isDefault))
+    .map((provider): AppDefinition => {
+      const known = appDefinitionMap[provider.key];
+      if (known) {
+        return known;
+      }
+
+      const tabs: WorkspaceTabKey[] = [];
+      if (provider.capabilities.includes('users')) {
+        tabs.push('users');
+      }
+      if (provider.capabilities.includes('configs')) {
+        tabs.push('configs');
+      }
+      return {
+        key: provider.key,
+        label: provider.displayName || provider.key,
+        logo: APPBOX_LOGO,
+        description: provider.description,
+        userVariant: 'tinytext',
+        tabs,
+      };
+    })
+    .filter((item) => item.tabs.length > 0);
 }
 
 function resolveTabLabel(tab: WorkspaceTabKey): string {
@@ -139,12 +162,12 @@ export default function App(): JSX.Element | null {
       setLoadingWorkspace(true);
       setWorkspaceError('');
       try {
-        const providerKeys = await listProviders();
+        const providers = await listProviders();
         if (cancelled) {
           return;
         }
 
-        const nextApps = resolveAvailableApps(providerKeys);
+        const nextApps = resolveAvailableApps(providers);
 
         if (nextApps.length === 0) {
           throw new Error('当前环境未启用受支持的应用 provider');
@@ -3,14 +3,21 @@ import type {
   AdminUsersPaginationResponse,
   AppConfig,
   AppConfigUpsertRequest,
+  OperatorPermissions,
   PaginationResponse,
   PlanetItem,
+  ProviderItem,
   User,
+  UserSearchResponse,
   AdminUserUpdateRequest
 } from '../types/api';
 
-export async function listProviders(): Promise<string[]> {
-  return request<string[]>('/admin/providers', { method: 'GET' });
+export async function listProviders(): Promise<ProviderItem[]> {
+  return request<ProviderItem[]>('/admin/providers', { method: 'GET' });
+}
+
+export async function getMyPermissions(): Promise<OperatorPermissions> {
+  return request<OperatorPermissions>('/admin/me/permissions', { method: 'GET' });
 }
 
 export async function listUsers(
@@ -33,6 +40,18 @@ export async function listUsers(
   });
 }
 
+export async function searchUsers(keyword: string, providers: string[] = [], pageSize = 10): Promise<UserSearchResponse> {
+  const params = new URLSearchParams({
+    keyword: keyword.trim(),
+    pageSize: String(pageSize)
+  });
+  if (providers.length > 0) {
+    params.set('providers', providers.join(','));
+  }
+
+  return request<UserSearchResponse>(`/admin/search/users?${params.toString()}`, { method: 'GET' });
+}
+
 export async function updateUser(userId: number, payload: AdminUserUpdateRequest, appKey = ''): Promise<User> {
   return request<User>(`/admin/users/${userId}`, {
     method: 'PUT',
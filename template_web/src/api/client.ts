@@ -79,7 +79,8 @@ export async function request<T>(path: string, options: RequestOptions = {}): Pr
 
   if (!response.ok || !payload || payload.code !== 200) {
     const message = payload?.msg || resolveRequestFailureMessage(response, rawText);
-    throw new Error(message);
+    const requestId = payload?.requestId || response.headers.get('X-Request-ID');
+    throw new Error(requestId ? `${message}（请求 ID: ${requestId}）` : message);
   }
 
   return payload.data;
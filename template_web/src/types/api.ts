@@ -3,6 +3,27 @@ export interface ApiResponse<T> {
   timestamp: number;
   msg: string;
   data: T;
+  requestId?: string;
+}
+
+export interface ProviderHealth {
+  status: 'up' | 'down' | 'unknown' | 'disabled';
+  latencyMs: number;
+  lastError?: string;
+  version?: string;
+  checkedAt?: number;
+}
+
+export interface ProviderItem {
+  key: string;
+  displayName: string;
+  description: string;
+  isDefault: boolean;
+  baseUrlHost: string;
+  capabilities: string[];
+  circuitState: 'disabled' | 'closed' | 'open' | 'half-open';
+  health: ProviderHealth;
+  version: string;
 }
 
 export interface PaginationResponse<T> {
@@ -72,3 +93,33 @@ export interface AppConfigUpsertRequest {
   valueType: 'string' | 'number' | 'boolean' | 'json';
   description: string;
 }
+
+export interface UserSearchItem extends User {
+  provider: string;
+}
+
+export interface ProviderSearchState {
+  provider: string;
+  status: 'ok' | 'failed' | 'skipped';
+  total: number;
+  count: number;
+  latencyMs: number;
+  result?: string;
+  statusCode?: number;
+  error?: string;
+}
+
+export interface UserSearchResponse {
+  keyword: string;
+  items: UserSearchItem[];
+  providers: ProviderSearchState[];
+  partial: boolean;
+}
+
+export interface OperatorPermissions {
+  operator: string;
+  roles: string[];
+  rbacEnabled: boolean;
+  providers: Record<string, string[]>;
+  gateway: string[];
+}